@@ -5,6 +5,7 @@ import (
 	"github.com/gogf/gf/v2/os/gtime"
 	"github.com/iimeta/fastapi-sdk/logger"
 	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
 	"github.com/iimeta/go-openai"
 )
 
@@ -49,3 +50,11 @@ func (c *Client) Image(ctx context.Context, request model.ImageRequest) (res mod
 
 	return res, nil
 }
+
+func (c *Client) ImageEdit(ctx context.Context, request model.ImageEditRequest) (res model.ImageResponse, err error) {
+	return res, sdkerr.NewApiError(400, "unsupported_operation", "360AI does not support image edit.", "invalid_request_error", "")
+}
+
+func (c *Client) ImageVariation(ctx context.Context, request model.ImageVariationRequest) (res model.ImageResponse, err error) {
+	return res, sdkerr.NewApiError(400, "unsupported_operation", "360AI does not support image variation.", "invalid_request_error", "")
+}
@@ -27,7 +27,7 @@ func NewClient(ctx context.Context, model, key, baseURL, path string, isSupportS
 	client := &Client{
 		key:                 key,
 		baseURL:             "https://dashscope.aliyuncs.com/api/v1",
-		path:                "/services/aigc/text-generation/generation",
+		path:                textGenerationPath,
 		isSupportSystemRole: isSupportSystemRole,
 	}
 
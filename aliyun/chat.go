@@ -7,6 +7,7 @@ import (
 	"github.com/gogf/gf/v2/encoding/gjson"
 	"github.com/gogf/gf/v2/os/grpool"
 	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
 	"github.com/gogf/gf/v2/util/gconv"
 	"github.com/gogf/gf/v2/util/grand"
 	"github.com/iimeta/fastapi-sdk/common"
@@ -56,11 +57,25 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 		chatCompletionReq.Parameters.ResultFormat = gconv.String(request.ResponseFormat.Type)
 	}
 
+	if request.EnableSearch {
+		chatCompletionReq.Parameters.EnableSearch = true
+		chatCompletionReq.Parameters.SearchOptions = &model.AliyunSearchOptions{
+			EnableSource:   true,
+			EnableCitation: true,
+		}
+	}
+
+	path := c.getPath(request.Model)
+	if gstr.Contains(path, "multimodal-generation") {
+		chatCompletionReq.Input.Messages = convMultimodalMessages(messages)
+		chatCompletionReq.Parameters.ResultFormat = "message"
+	}
+
 	header := make(map[string]string)
 	header["Authorization"] = "Bearer " + c.key
 
 	chatCompletionRes := new(model.AliyunChatCompletionRes)
-	if _, err = util.HttpPost(ctx, c.baseURL+c.path, header, chatCompletionReq, &chatCompletionRes, c.proxyURL); err != nil {
+	if _, err = util.HttpPost(ctx, c.baseURL+path, header, chatCompletionReq, &chatCompletionRes, c.proxyURL); err != nil {
 		logger.Errorf(ctx, "ChatCompletion Aliyun model: %s, error: %v", request.Model, err)
 		return
 	}
@@ -74,6 +89,16 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 		return
 	}
 
+	var (
+		content = chatCompletionRes.Output.Text
+		audio   *openai.Audio
+	)
+
+	// result_format为message时, 内容在choices中返回
+	if content == "" && len(chatCompletionRes.Output.Choices) > 0 && chatCompletionRes.Output.Choices[0].Message != nil {
+		content, audio = convMultimodalContent(chatCompletionRes.Output.Choices[0].Message.Content)
+	}
+
 	res = model.ChatCompletionResponse{
 		ID:      consts.COMPLETION_ID_PREFIX + chatCompletionRes.RequestId,
 		Object:  consts.COMPLETION_OBJECT,
@@ -82,7 +107,8 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 		Choices: []model.ChatCompletionChoice{{
 			Message: &model.ChatCompletionMessage{
 				Role:    consts.ROLE_ASSISTANT,
-				Content: chatCompletionRes.Output.Text,
+				Content: content,
+				Audio:   audio,
 			},
 		}},
 		Usage: &model.Usage{
@@ -92,6 +118,11 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 		},
 	}
 
+	if chatCompletionRes.Output.SearchInfo != nil {
+		res.Citations = common.ConvSearchResults(chatCompletionRes.Output.SearchInfo.SearchResults)
+		res.Choices[0].Message.Citations = res.Citations
+	}
+
 	return res, nil
 }
 
@@ -136,10 +167,24 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 		chatCompletionReq.Parameters.ResultFormat = gconv.String(request.ResponseFormat.Type)
 	}
 
+	if request.EnableSearch {
+		chatCompletionReq.Parameters.EnableSearch = true
+		chatCompletionReq.Parameters.SearchOptions = &model.AliyunSearchOptions{
+			EnableSource:   true,
+			EnableCitation: true,
+		}
+	}
+
+	path := c.getPath(request.Model)
+	if gstr.Contains(path, "multimodal-generation") {
+		chatCompletionReq.Input.Messages = convMultimodalMessages(messages)
+		chatCompletionReq.Parameters.ResultFormat = "message"
+	}
+
 	header := make(map[string]string)
 	header["Authorization"] = "Bearer " + c.key
 
-	stream, err := util.SSEClient(ctx, c.baseURL+c.path, header, chatCompletionReq, c.proxyURL, c.requestErrorHandler)
+	stream, err := util.SSEClient(ctx, c.baseURL+path, header, chatCompletionReq, c.proxyURL, c.requestErrorHandler)
 	if err != nil {
 		logger.Errorf(ctx, "ChatCompletionStream Aliyun model: %s, error: %v", request.Model, err)
 		return responseChan, err
@@ -161,9 +206,10 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 		}()
 
 		var (
-			usage   *model.Usage
-			created = gtime.Timestamp()
-			id      = consts.COMPLETION_ID_PREFIX + grand.S(29)
+			usage           *model.Usage
+			created         = gtime.Timestamp()
+			id              = consts.COMPLETION_ID_PREFIX + grand.S(29)
+			isCitationsSent = false
 		)
 
 		for {
@@ -254,6 +300,16 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 				TotalTokens:      chatCompletionRes.Usage.InputTokens + chatCompletionRes.Usage.OutputTokens,
 			}
 
+			var (
+				content = chatCompletionRes.Output.Text
+				audio   *openai.Audio
+			)
+
+			// result_format为message时, 内容在choices中返回
+			if content == "" && len(chatCompletionRes.Output.Choices) > 0 && chatCompletionRes.Output.Choices[0].Message != nil {
+				content, audio = convMultimodalContent(chatCompletionRes.Output.Choices[0].Message.Content)
+			}
+
 			response := &model.ChatCompletionResponse{
 				ID:      id,
 				Object:  consts.COMPLETION_STREAM_OBJECT,
@@ -262,13 +318,21 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 				Choices: []model.ChatCompletionChoice{{
 					Delta: &model.ChatCompletionStreamChoiceDelta{
 						Role:    consts.ROLE_ASSISTANT,
-						Content: chatCompletionRes.Output.Text,
+						Content: content,
+						Audio:   audio,
 					},
 				}},
 				Usage:    usage,
 				ConnTime: duration - now,
 			}
 
+			// 搜索信息只在首次返回时下发
+			if chatCompletionRes.Output.SearchInfo != nil && len(chatCompletionRes.Output.SearchInfo.SearchResults) > 0 && !isCitationsSent {
+				response.Citations = common.ConvSearchResults(chatCompletionRes.Output.SearchInfo.SearchResults)
+				response.Choices[0].Delta.Citations = response.Citations
+				isCitationsSent = true
+			}
+
 			end := gtime.TimestampMilli()
 			response.Duration = end - duration
 			response.TotalTime = end - now
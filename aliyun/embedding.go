@@ -2,10 +2,115 @@ package aliyun
 
 import (
 	"context"
+	"fmt"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/iimeta/fastapi-sdk/common"
+	"github.com/iimeta/fastapi-sdk/logger"
 	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
+	"github.com/iimeta/fastapi-sdk/util"
+	"github.com/iimeta/go-openai"
+)
+
+const embeddingPath = "/services/embeddings/text-embedding/text-embedding"
+
+// 单次请求最多支持25条文本, text-embedding-v3及以后的模型最多支持10条
+const (
+	embeddingBatchSize   = 25
+	embeddingV3BatchSize = 10
 )
 
 func (c *Client) Embeddings(ctx context.Context, request model.EmbeddingRequest) (res model.EmbeddingResponse, err error) {
-	//TODO implement me
-	panic("implement me")
+
+	logger.Infof(ctx, "Embeddings Aliyun model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "Embeddings Aliyun model: %s totalTime: %d ms", request.Model, res.TotalTime)
+	}()
+
+	inputs, err := common.EmbeddingInputs(request.Input)
+	if err != nil {
+		logger.Errorf(ctx, "Embeddings Aliyun model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	// 配置的path为对话等其它接口时使用默认的向量接口
+	path := c.path
+	if !gstr.Contains(path, "/embeddings/") {
+		path = embeddingPath
+	}
+
+	batchSize := embeddingBatchSize
+	if !gstr.HasSuffix(string(request.Model), "-v1") && !gstr.HasSuffix(string(request.Model), "-v2") {
+		batchSize = embeddingV3BatchSize
+	}
+
+	header := make(map[string]string)
+	header["Authorization"] = "Bearer " + c.key
+
+	res = model.EmbeddingResponse{
+		Object: "list",
+		Model:  request.Model,
+		Usage:  new(model.Usage),
+	}
+
+	for i := 0; i < len(inputs); i += batchSize {
+
+		batchInputs := inputs[i:min(i+batchSize, len(inputs))]
+
+		embeddingReq := model.AliyunEmbeddingReq{
+			Model: string(request.Model),
+			Input: model.AliyunEmbeddingInput{
+				Texts: batchInputs,
+			},
+			Parameters: model.AliyunEmbeddingParameters{
+				Dimension: request.Dimensions,
+				TextType:  request.TextType,
+			},
+		}
+
+		embeddingRes := new(model.AliyunEmbeddingRes)
+		if _, err = util.HttpPost(ctx, c.baseURL+path, header, embeddingReq, &embeddingRes, c.proxyURL); err != nil {
+			logger.Errorf(ctx, "Embeddings Aliyun model: %s, error: %v", request.Model, err)
+			return res, err
+		}
+
+		if embeddingRes.Code != "" {
+			logger.Errorf(ctx, "Embeddings Aliyun model: %s, embeddingRes: %s", request.Model, gjson.MustEncodeString(embeddingRes))
+
+			err = sdkerr.NewApiError(500, embeddingRes.Code, gjson.MustEncodeString(embeddingRes), "api_error", "")
+			logger.Errorf(ctx, "Embeddings Aliyun model: %s, error: %v", request.Model, err)
+
+			return res, err
+		}
+
+		// 返回的向量数量与输入不一致时无法对应index
+		if len(embeddingRes.Output.Embeddings) != len(batchInputs) {
+			logger.Errorf(ctx, "Embeddings Aliyun model: %s, embeddingRes: %s", request.Model, gjson.MustEncodeString(embeddingRes))
+
+			err = sdkerr.NewApiError(500, "embedding_count_mismatch", fmt.Sprintf("Expected %d embeddings, got %d.", len(batchInputs), len(embeddingRes.Output.Embeddings)), "api_error", "")
+			logger.Errorf(ctx, "Embeddings Aliyun model: %s, error: %v", request.Model, err)
+
+			return res, err
+		}
+
+		for _, embedding := range embeddingRes.Output.Embeddings {
+			res.Data = append(res.Data, openai.Embedding{
+				Object:    "embedding",
+				Embedding: embedding.Embedding,
+				Index:     i + embedding.TextIndex,
+			})
+		}
+
+		res.Usage.PromptTokens += embeddingRes.Usage.TotalTokens
+		res.Usage.TotalTokens += embeddingRes.Usage.TotalTokens
+	}
+
+	logger.Infof(ctx, "Embeddings Aliyun model: %s finished", request.Model)
+
+	return res, nil
 }
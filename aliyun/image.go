@@ -2,10 +2,146 @@ package aliyun
 
 import (
 	"context"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/iimeta/fastapi-sdk/common"
+	"github.com/iimeta/fastapi-sdk/logger"
 	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
+	"github.com/iimeta/fastapi-sdk/util"
+	"time"
 )
 
+// 异步任务轮询间隔
+const taskPollInterval = 3 * time.Second
+
 func (c *Client) Image(ctx context.Context, request model.ImageRequest) (res model.ImageResponse, err error) {
-	//TODO implement me
-	panic("implement me")
+	return res, sdkerr.NewApiError(400, "unsupported_operation", "Aliyun does not support image generation.", "invalid_request_error", "")
+}
+
+func (c *Client) ImageEdit(ctx context.Context, request model.ImageEditRequest) (res model.ImageResponse, err error) {
+
+	logger.Infof(ctx, "ImageEdit Aliyun model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "ImageEdit Aliyun model: %s totalTime: %d ms", request.Model, gtime.TimestampMilli()-now)
+	}()
+
+	imageEditReq := model.AliyunImageEditReq{
+		Model: request.Model,
+		Input: model.AliyunImageEditInput{
+			Function: request.Function,
+			Prompt:   request.Prompt,
+		},
+		Parameters: model.AliyunImageEditParameters{
+			N: request.N,
+		},
+	}
+
+	if imageEditReq.Model == "" {
+		imageEditReq.Model = "wanx2.1-imageedit"
+	}
+
+	if imageEditReq.Input.BaseImageUrl, err = common.ImageFileURL(request.Image); err != nil {
+		logger.Errorf(ctx, "ImageEdit Aliyun model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	// 万相的遮罩白色为编辑区域, 黑色为保留区域, 需从OpenAI格式的透明遮罩转换
+	if request.Mask != nil {
+		if imageEditReq.Input.MaskImageUrl, err = common.ConvAlphaMask(request.Mask); err != nil {
+			logger.Errorf(ctx, "ImageEdit Aliyun model: %s, error: %v", request.Model, err)
+			return res, err
+		}
+	}
+
+	if imageEditReq.Input.Function == "" {
+		if request.Mask != nil {
+			imageEditReq.Input.Function = "description_edit_with_mask"
+		} else {
+			imageEditReq.Input.Function = "description_edit"
+		}
+	}
+
+	header := make(map[string]string)
+	header["Authorization"] = "Bearer " + c.key
+	header["X-DashScope-Async"] = "enable"
+
+	taskRes := new(model.AliyunTaskRes)
+	if _, err = util.HttpPost(ctx, c.baseURL+"/services/aigc/image2image/image-synthesis", header, imageEditReq, &taskRes, c.proxyURL); err != nil {
+		logger.Errorf(ctx, "ImageEdit Aliyun model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	if taskRes.Code != "" {
+		logger.Errorf(ctx, "ImageEdit Aliyun model: %s, taskRes: %s", request.Model, gjson.MustEncodeString(taskRes))
+
+		err = sdkerr.NewApiError(500, taskRes.Code, gjson.MustEncodeString(taskRes), "api_error", "")
+		logger.Errorf(ctx, "ImageEdit Aliyun model: %s, error: %v", request.Model, err)
+
+		return res, err
+	}
+
+	if taskRes, err = c.waitTask(ctx, taskRes.Output.TaskId); err != nil {
+		logger.Errorf(ctx, "ImageEdit Aliyun model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	res.Created = gtime.Timestamp()
+
+	for _, result := range taskRes.Output.Results {
+		if result.Url != "" {
+			res.Data = append(res.Data, model.ImageResponseDataInner{
+				URL: result.Url,
+			})
+		}
+	}
+
+	if len(res.Data) == 0 {
+		err = sdkerr.NewApiError(500, "image_edit_failed", gjson.MustEncodeString(taskRes), "api_error", "")
+		logger.Errorf(ctx, "ImageEdit Aliyun model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	logger.Infof(ctx, "ImageEdit Aliyun model: %s finished", request.Model)
+
+	return res, nil
+}
+
+func (c *Client) ImageVariation(ctx context.Context, request model.ImageVariationRequest) (res model.ImageResponse, err error) {
+	return res, sdkerr.NewApiError(400, "unsupported_operation", "Aliyun does not support image variation.", "invalid_request_error", "")
+}
+
+// 轮询异步任务直到结束或ctx被取消
+func (c *Client) waitTask(ctx context.Context, taskId string) (taskRes *model.AliyunTaskRes, err error) {
+
+	header := make(map[string]string)
+	header["Authorization"] = "Bearer " + c.key
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return taskRes, ctx.Err()
+		case <-time.After(taskPollInterval):
+		}
+
+		taskRes = new(model.AliyunTaskRes)
+		if _, err = util.HttpGet(ctx, c.baseURL+"/tasks/"+taskId, header, nil, &taskRes, c.proxyURL); err != nil {
+			return taskRes, err
+		}
+
+		if taskRes.Code != "" {
+			return taskRes, sdkerr.NewApiError(500, taskRes.Code, gjson.MustEncodeString(taskRes), "api_error", "")
+		}
+
+		switch taskRes.Output.TaskStatus {
+		case "SUCCEEDED":
+			return taskRes, nil
+		case "FAILED", "CANCELED", "UNKNOWN":
+			return taskRes, sdkerr.NewApiError(500, taskRes.Output.Code, gjson.MustEncodeString(taskRes), "api_error", "")
+		}
+	}
 }
@@ -0,0 +1,131 @@
+package aliyun
+
+import (
+	"fmt"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/go-openai"
+)
+
+const (
+	textGenerationPath       = "/services/aigc/text-generation/generation"
+	multimodalGenerationPath = "/services/aigc/multimodal-generation/generation"
+)
+
+// isMultimodal 通义千问VL、Audio模型需调用multimodal-generation接口
+func isMultimodal(model string) bool {
+
+	model = gstr.ToLower(model)
+
+	return gstr.Contains(model, "-vl") || gstr.HasPrefix(model, "qwen-audio") || gstr.HasPrefix(model, "qwen2-audio") || gstr.HasPrefix(model, "qvq")
+}
+
+// getPath 未自定义path时, 多模态模型使用multimodal-generation接口
+func (c *Client) getPath(model string) string {
+
+	if c.path == textGenerationPath && isMultimodal(model) {
+		return multimodalGenerationPath
+	}
+
+	return c.path
+}
+
+// convMultimodalMessages 将OpenAI格式的content转换为DashScope多模态格式, 如[{"image": "..."}, {"text": "..."}]
+func convMultimodalMessages(messages []model.ChatCompletionMessage) []model.ChatCompletionMessage {
+
+	multimodalMessages := make([]model.ChatCompletionMessage, 0, len(messages))
+
+	for _, message := range messages {
+
+		contents := make([]g.Map, 0)
+
+		if values, ok := message.Content.([]interface{}); ok {
+
+			for _, value := range values {
+
+				content, ok := value.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				switch content["type"] {
+				case "image_url":
+					if imageUrl, ok := content["image_url"].(map[string]interface{}); ok {
+						contents = append(contents, g.Map{"image": gconv.String(imageUrl["url"])})
+					} else {
+						contents = append(contents, g.Map{"image": gconv.String(content["image_url"])})
+					}
+				case "input_audio":
+					if inputAudio, ok := content["input_audio"].(map[string]interface{}); ok {
+
+						data := gconv.String(inputAudio["data"])
+
+						// base64编码的音频转换为data URI, URL直接使用
+						if !gstr.HasPrefix(data, "http") && !gstr.HasPrefix(data, "data:") {
+							data = fmt.Sprintf("data:audio/%s;base64,%s", gconv.String(inputAudio["format"]), data)
+						}
+
+						contents = append(contents, g.Map{"audio": data})
+					}
+				case "video_url":
+					if videoUrl, ok := content["video_url"].(map[string]interface{}); ok {
+						contents = append(contents, g.Map{"video": videoUrl["url"]})
+					} else {
+						contents = append(contents, g.Map{"video": content["video_url"]})
+					}
+				default:
+					if text := gconv.String(content["text"]); text != "" {
+						contents = append(contents, g.Map{"text": text})
+					}
+				}
+			}
+
+		} else if text := gconv.String(message.Content); text != "" {
+			contents = append(contents, g.Map{"text": text})
+		}
+
+		message.Content = contents
+		multimodalMessages = append(multimodalMessages, message)
+	}
+
+	return multimodalMessages
+}
+
+// convMultimodalContent 解析多模态接口返回的content, 如[{"text": "..."}], 文本拼接返回, 音频转换为Audio
+func convMultimodalContent(content any) (text string, audio *openai.Audio) {
+
+	values, ok := content.([]interface{})
+	if !ok {
+		return gconv.String(content), nil
+	}
+
+	for _, value := range values {
+
+		item := gconv.Map(value)
+
+		text += gconv.String(item["text"])
+
+		if data, ok := item["audio"]; ok {
+
+			if audioData, ok := data.(map[string]interface{}); ok {
+				audio = &openai.Audio{
+					Id:        gconv.String(audioData["id"]),
+					Data:      gconv.String(audioData["data"]),
+					ExpiresAt: gconv.Int(audioData["expires_at"]),
+				}
+			} else {
+				audio = &openai.Audio{
+					Data: gconv.String(data),
+				}
+			}
+		}
+	}
+
+	if audio != nil {
+		audio.Transcript = text
+	}
+
+	return text, audio
+}
@@ -0,0 +1,90 @@
+package aliyun
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/iimeta/fastapi-sdk/consts"
+	"github.com/iimeta/fastapi-sdk/model"
+)
+
+func TestConvMultimodalMessages(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		content any
+		want    []g.Map
+	}{
+		{
+			name:    "plain text",
+			content: "describe this",
+			want:    []g.Map{{"text": "describe this"}},
+		},
+		{
+			name: "image url object and text",
+			content: []interface{}{
+				map[string]interface{}{"type": "image_url", "image_url": map[string]interface{}{"url": "https://example.com/cat.png"}},
+				map[string]interface{}{"type": "text", "text": "what is it?"},
+			},
+			want: []g.Map{{"image": "https://example.com/cat.png"}, {"text": "what is it?"}},
+		},
+		{
+			name: "image url string",
+			content: []interface{}{
+				map[string]interface{}{"type": "image_url", "image_url": "https://example.com/cat.png"},
+			},
+			want: []g.Map{{"image": "https://example.com/cat.png"}},
+		},
+		{
+			name: "base64 audio becomes data uri",
+			content: []interface{}{
+				map[string]interface{}{"type": "input_audio", "input_audio": map[string]interface{}{"data": "UklGRg==", "format": "wav"}},
+			},
+			want: []g.Map{{"audio": "data:audio/wav;base64,UklGRg=="}},
+		},
+		{
+			name: "audio url is kept",
+			content: []interface{}{
+				map[string]interface{}{"type": "input_audio", "input_audio": map[string]interface{}{"data": "https://example.com/a.mp3", "format": "mp3"}},
+			},
+			want: []g.Map{{"audio": "https://example.com/a.mp3"}},
+		},
+		{
+			name: "video url",
+			content: []interface{}{
+				map[string]interface{}{"type": "video_url", "video_url": map[string]interface{}{"url": "https://example.com/v.mp4"}},
+			},
+			want: []g.Map{{"video": "https://example.com/v.mp4"}},
+		},
+		{
+			name: "empty text and unknown items are skipped",
+			content: []interface{}{
+				map[string]interface{}{"type": "text", "text": ""},
+				"not a content item",
+			},
+			want: []g.Map{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			messages := []model.ChatCompletionMessage{{Role: consts.ROLE_USER, Content: tt.content}}
+
+			got := convMultimodalMessages(messages)
+			if len(got) != 1 || got[0].Role != consts.ROLE_USER {
+				t.Fatalf("convMultimodalMessages() = %+v", got)
+			}
+
+			if !reflect.DeepEqual(got[0].Content, tt.want) {
+				t.Errorf("Content = %#v, want %#v", got[0].Content, tt.want)
+			}
+
+			// 不修改调用方的原始消息
+			if !reflect.DeepEqual(messages[0].Content, tt.content) {
+				t.Errorf("caller content = %#v, want %#v", messages[0].Content, tt.content)
+			}
+		})
+	}
+}
@@ -14,3 +14,8 @@ func (c *Client) Transcription(ctx context.Context, request model.AudioRequest)
 	//TODO implement me
 	panic("implement me")
 }
+
+func (c *Client) Translation(ctx context.Context, request model.AudioRequest) (res model.AudioResponse, err error) {
+	//TODO implement me
+	panic("implement me")
+}
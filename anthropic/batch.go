@@ -0,0 +1,309 @@
+package anthropic
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/grpool"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/iimeta/fastapi-sdk/consts"
+	"github.com/iimeta/fastapi-sdk/logger"
+	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
+	"github.com/iimeta/fastapi-sdk/util"
+	"io"
+	"time"
+)
+
+// 批处理结果单行最大长度
+const batchResultMaxLineSize = 32 * 1024 * 1024
+
+var errBatchNotSupported = errors.New("message batches are only supported by the Anthropic API")
+
+func (c *Client) CreateBatch(ctx context.Context, items []model.AnthropicBatchItem) (res model.AnthropicBatch, err error) {
+
+	logger.Infof(ctx, "CreateBatch Anthropic model: %s start", c.model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		logger.Infof(ctx, "CreateBatch Anthropic model: %s totalTime: %d ms", c.model, gtime.TimestampMilli()-now)
+	}()
+
+	if c.isAws || c.isGcp {
+		return res, errBatchNotSupported
+	}
+
+	batchReq := model.AnthropicBatchReq{
+		Requests: make([]model.AnthropicBatchRequest, 0),
+	}
+
+	for _, item := range items {
+
+		params := c.convChatCompletionReq(item.Request)
+		params.Stream = false
+
+		batchReq.Requests = append(batchReq.Requests, model.AnthropicBatchRequest{
+			CustomId: item.CustomId,
+			Params:   params,
+		})
+	}
+
+	if _, err = util.HttpPost(ctx, c.baseURL+"/messages/batches", c.header, batchReq, &res, c.proxyURL); err != nil {
+		logger.Errorf(ctx, "CreateBatch Anthropic model: %s, error: %v", c.model, err)
+		return res, err
+	}
+
+	if res.Error != nil && res.Error.Type != "" {
+		err = sdkerr.NewApiError(500, res.Error.Type, gjson.MustEncodeString(res), "api_error", "")
+		logger.Errorf(ctx, "CreateBatch Anthropic model: %s, error: %v", c.model, err)
+		return res, err
+	}
+
+	logger.Infof(ctx, "CreateBatch Anthropic model: %s, batchId: %s finished", c.model, res.Id)
+
+	return res, nil
+}
+
+func (c *Client) RetrieveBatch(ctx context.Context, batchId string) (res model.AnthropicBatch, err error) {
+
+	logger.Infof(ctx, "RetrieveBatch Anthropic model: %s, batchId: %s start", c.model, batchId)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		logger.Infof(ctx, "RetrieveBatch Anthropic model: %s, batchId: %s totalTime: %d ms", c.model, batchId, gtime.TimestampMilli()-now)
+	}()
+
+	if c.isAws || c.isGcp {
+		return res, errBatchNotSupported
+	}
+
+	if _, err = util.HttpGet(ctx, c.baseURL+"/messages/batches/"+batchId, c.header, nil, &res, c.proxyURL); err != nil {
+		logger.Errorf(ctx, "RetrieveBatch Anthropic model: %s, batchId: %s, error: %v", c.model, batchId, err)
+		return res, err
+	}
+
+	if res.Error != nil && res.Error.Type != "" {
+		err = sdkerr.NewApiError(500, res.Error.Type, gjson.MustEncodeString(res), "api_error", "")
+		logger.Errorf(ctx, "RetrieveBatch Anthropic model: %s, batchId: %s, error: %v", c.model, batchId, err)
+		return res, err
+	}
+
+	logger.Infof(ctx, "RetrieveBatch Anthropic model: %s, batchId: %s finished", c.model, batchId)
+
+	return res, nil
+}
+
+// ListBatches beforeId与afterId用于分页, limit为0时使用服务端默认值
+func (c *Client) ListBatches(ctx context.Context, beforeId, afterId string, limit int) (res model.AnthropicBatchList, err error) {
+
+	logger.Infof(ctx, "ListBatches Anthropic model: %s start", c.model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		logger.Infof(ctx, "ListBatches Anthropic model: %s totalTime: %d ms", c.model, gtime.TimestampMilli()-now)
+	}()
+
+	if c.isAws || c.isGcp {
+		return res, errBatchNotSupported
+	}
+
+	data := g.Map{}
+
+	if beforeId != "" {
+		data["before_id"] = beforeId
+	}
+
+	if afterId != "" {
+		data["after_id"] = afterId
+	}
+
+	if limit > 0 {
+		data["limit"] = limit
+	}
+
+	if _, err = util.HttpGet(ctx, c.baseURL+"/messages/batches", c.header, data, &res, c.proxyURL); err != nil {
+		logger.Errorf(ctx, "ListBatches Anthropic model: %s, error: %v", c.model, err)
+		return res, err
+	}
+
+	if res.Error != nil && res.Error.Type != "" {
+		err = sdkerr.NewApiError(500, res.Error.Type, gjson.MustEncodeString(res), "api_error", "")
+		logger.Errorf(ctx, "ListBatches Anthropic model: %s, error: %v", c.model, err)
+		return res, err
+	}
+
+	logger.Infof(ctx, "ListBatches Anthropic model: %s finished", c.model)
+
+	return res, nil
+}
+
+func (c *Client) CancelBatch(ctx context.Context, batchId string) (res model.AnthropicBatch, err error) {
+
+	logger.Infof(ctx, "CancelBatch Anthropic model: %s, batchId: %s start", c.model, batchId)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		logger.Infof(ctx, "CancelBatch Anthropic model: %s, batchId: %s totalTime: %d ms", c.model, batchId, gtime.TimestampMilli()-now)
+	}()
+
+	if c.isAws || c.isGcp {
+		return res, errBatchNotSupported
+	}
+
+	if _, err = util.HttpPost(ctx, c.baseURL+"/messages/batches/"+batchId+"/cancel", c.header, nil, &res, c.proxyURL); err != nil {
+		logger.Errorf(ctx, "CancelBatch Anthropic model: %s, batchId: %s, error: %v", c.model, batchId, err)
+		return res, err
+	}
+
+	if res.Error != nil && res.Error.Type != "" {
+		err = sdkerr.NewApiError(500, res.Error.Type, gjson.MustEncodeString(res), "api_error", "")
+		logger.Errorf(ctx, "CancelBatch Anthropic model: %s, batchId: %s, error: %v", c.model, batchId, err)
+		return res, err
+	}
+
+	logger.Infof(ctx, "CancelBatch Anthropic model: %s, batchId: %s finished", c.model, batchId)
+
+	return res, nil
+}
+
+// WaitBatch 按interval轮询批处理状态, 直到处理结束或ctx被取消
+func (c *Client) WaitBatch(ctx context.Context, batchId string, interval time.Duration) (res model.AnthropicBatch, err error) {
+
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	for {
+
+		if res, err = c.RetrieveBatch(ctx, batchId); err != nil {
+			return res, err
+		}
+
+		if res.ProcessingStatus == consts.BATCH_STATUS_ENDED {
+			return res, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// BatchResults 逐行读取JSONL格式的批处理结果, 成功的结果转换为OpenAI格式的响应, 读取完毕时返回Err为io.EOF的结果
+func (c *Client) BatchResults(ctx context.Context, batchId string) (responseChan chan *model.AnthropicBatchResult, err error) {
+
+	logger.Infof(ctx, "BatchResults Anthropic model: %s, batchId: %s start", c.model, batchId)
+
+	now := gtime.TimestampMilli()
+
+	if c.isAws || c.isGcp {
+		return responseChan, errBatchNotSupported
+	}
+
+	client := g.Client()
+	client.SetHeaderMap(c.header)
+
+	if c.proxyURL != "" {
+		client.SetProxy(c.proxyURL)
+	}
+
+	response, err := client.Get(ctx, c.baseURL+"/messages/batches/"+batchId+"/results")
+	if err != nil {
+		logger.Errorf(ctx, "BatchResults Anthropic model: %s, batchId: %s, error: %v", c.model, batchId, err)
+		return responseChan, err
+	}
+
+	if response.StatusCode != 200 {
+
+		defer func() {
+			if err := response.Close(); err != nil {
+				logger.Error(ctx, err)
+			}
+		}()
+
+		err = c.requestErrorHandler(ctx, response)
+		logger.Errorf(ctx, "BatchResults Anthropic model: %s, batchId: %s, error: %v", c.model, batchId, err)
+
+		return responseChan, err
+	}
+
+	responseChan = make(chan *model.AnthropicBatchResult)
+
+	if err = grpool.AddWithRecover(ctx, func(ctx context.Context) {
+
+		defer func() {
+			if err := response.Close(); err != nil {
+				logger.Errorf(ctx, "BatchResults Anthropic model: %s, batchId: %s, response.Close error: %v", c.model, batchId, err)
+			}
+
+			logger.Infof(ctx, "BatchResults Anthropic model: %s, batchId: %s totalTime: %d ms", c.model, batchId, gtime.TimestampMilli()-now)
+		}()
+
+		scanner := bufio.NewScanner(response.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), batchResultMaxLineSize)
+
+		for scanner.Scan() {
+
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+
+			result := new(model.AnthropicBatchResult)
+			if err := gjson.Unmarshal(line, &result); err != nil {
+				logger.Errorf(ctx, "BatchResults Anthropic model: %s, batchId: %s, line: %s, error: %v", c.model, batchId, line, err)
+				result.Err = errors.New(fmt.Sprintf("line: %s, error: %v", line, err))
+			} else {
+				c.convBatchResult(result)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case responseChan <- result:
+			}
+		}
+
+		err := scanner.Err()
+		if err != nil {
+			logger.Errorf(ctx, "BatchResults Anthropic model: %s, batchId: %s, error: %v", c.model, batchId, err)
+		} else {
+			err = io.EOF
+		}
+
+		select {
+		case <-ctx.Done():
+		case responseChan <- &model.AnthropicBatchResult{Err: err}:
+		}
+
+	}, nil); err != nil {
+		logger.Errorf(ctx, "BatchResults Anthropic model: %s, batchId: %s, error: %v", c.model, batchId, err)
+		return responseChan, err
+	}
+
+	return responseChan, nil
+}
+
+func (c *Client) convBatchResult(result *model.AnthropicBatchResult) {
+
+	switch result.Result.Type {
+	case consts.BATCH_RESULT_SUCCEEDED:
+		if result.Result.Message != nil {
+			response := convChatCompletionRes(result.Result.Message.Model, result.Result.Message)
+			result.Response = &response
+		}
+	case consts.BATCH_RESULT_ERRORED:
+		if result.Result.Error != nil && result.Result.Error.Error != nil {
+			result.Err = sdkerr.NewApiError(500, result.Result.Error.Error.Type, result.Result.Error.Error.Message, "api_error", "")
+		} else {
+			result.Err = sdkerr.NewApiError(500, result.Result.Type, gjson.MustEncodeString(result.Result), "api_error", "")
+		}
+	default:
+		result.Err = sdkerr.NewApiError(500, result.Result.Type, fmt.Sprintf("batch request %s was %s", result.CustomId, result.Result.Type), "api_error", "")
+	}
+}
@@ -20,6 +20,9 @@ import (
 	"io"
 )
 
+// Anthropic单次请求最多支持4个缓存断点
+const maxCacheBreakpoints = 4
+
 func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletionRequest) (res model.ChatCompletionResponse, err error) {
 
 	logger.Infof(ctx, "ChatCompletion Anthropic model: %s start", request.Model)
@@ -30,69 +33,7 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 		logger.Infof(ctx, "ChatCompletion Anthropic model: %s totalTime: %d ms", request.Model, res.TotalTime)
 	}()
 
-	var messages []model.ChatCompletionMessage
-	if c.isSupportSystemRole != nil {
-		messages = common.HandleMessages(request.Messages, *c.isSupportSystemRole)
-	} else {
-		messages = common.HandleMessages(request.Messages, true)
-	}
-
-	chatCompletionReq := model.AnthropicChatCompletionReq{
-		Model:         request.Model,
-		Messages:      messages,
-		MaxTokens:     request.MaxTokens,
-		StopSequences: request.Stop,
-		Stream:        request.Stream,
-		Temperature:   request.Temperature,
-		ToolChoice:    request.ToolChoice,
-		TopK:          request.TopK,
-		TopP:          request.TopP,
-		Tools:         request.Tools,
-	}
-
-	if chatCompletionReq.Messages[0].Role == consts.ROLE_SYSTEM {
-		chatCompletionReq.System = chatCompletionReq.Messages[0].Content
-		chatCompletionReq.Messages = chatCompletionReq.Messages[1:]
-	}
-
-	if request.User != "" {
-		chatCompletionReq.Metadata = &model.Metadata{
-			UserId: request.User,
-		}
-	}
-
-	if chatCompletionReq.MaxTokens == 0 {
-		chatCompletionReq.MaxTokens = 4096
-	}
-
-	for _, message := range messages {
-
-		if contents, ok := message.Content.([]interface{}); ok {
-
-			for _, value := range contents {
-
-				if content, ok := value.(map[string]interface{}); ok {
-
-					if content["type"] == "image_url" {
-
-						if imageUrl, ok := content["image_url"].(map[string]interface{}); ok {
-
-							mimeType, data := common.GetMime(gconv.String(imageUrl["url"]))
-
-							content["source"] = model.Source{
-								Type:      "base64",
-								MediaType: mimeType,
-								Data:      data,
-							}
-
-							content["type"] = "image"
-							delete(content, "image_url")
-						}
-					}
-				}
-			}
-		}
-	}
+	chatCompletionReq := c.convChatCompletionReq(request)
 
 	if c.isGcp {
 		chatCompletionReq.Model = ""
@@ -146,42 +87,7 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 		return res, err
 	}
 
-	res = model.ChatCompletionResponse{
-		ID:      consts.COMPLETION_ID_PREFIX + chatCompletionRes.Id,
-		Object:  consts.COMPLETION_OBJECT,
-		Created: gtime.Timestamp(),
-		Model:   request.Model,
-		Usage: &model.Usage{
-			PromptTokens:             chatCompletionRes.Usage.InputTokens,
-			CompletionTokens:         chatCompletionRes.Usage.OutputTokens,
-			TotalTokens:              chatCompletionRes.Usage.InputTokens + chatCompletionRes.Usage.OutputTokens,
-			CacheCreationInputTokens: chatCompletionRes.Usage.CacheCreationInputTokens,
-			CacheReadInputTokens:     chatCompletionRes.Usage.CacheReadInputTokens,
-		},
-	}
-
-	for _, content := range chatCompletionRes.Content {
-		if content.Type == consts.DELTA_TYPE_INPUT_JSON {
-			res.Choices = append(res.Choices, model.ChatCompletionChoice{
-				Delta: &model.ChatCompletionStreamChoiceDelta{
-					Role: consts.ROLE_ASSISTANT,
-					ToolCalls: []openai.ToolCall{{
-						Function: openai.FunctionCall{
-							Arguments: content.PartialJson,
-						},
-					}},
-				},
-			})
-		} else {
-			res.Choices = append(res.Choices, model.ChatCompletionChoice{
-				Message: &model.ChatCompletionMessage{
-					Role:    chatCompletionRes.Role,
-					Content: content.Text,
-				},
-				FinishReason: "stop",
-			})
-		}
-	}
+	res = convChatCompletionRes(request.Model, chatCompletionRes)
 
 	return res, nil
 }
@@ -197,69 +103,7 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 		}
 	}()
 
-	var messages []model.ChatCompletionMessage
-	if c.isSupportSystemRole != nil {
-		messages = common.HandleMessages(request.Messages, *c.isSupportSystemRole)
-	} else {
-		messages = common.HandleMessages(request.Messages, true)
-	}
-
-	chatCompletionReq := model.AnthropicChatCompletionReq{
-		Model:         request.Model,
-		Messages:      messages,
-		MaxTokens:     request.MaxTokens,
-		StopSequences: request.Stop,
-		Stream:        request.Stream,
-		Temperature:   request.Temperature,
-		ToolChoice:    request.ToolChoice,
-		TopK:          request.TopK,
-		TopP:          request.TopP,
-		Tools:         request.Tools,
-	}
-
-	if chatCompletionReq.Messages[0].Role == consts.ROLE_SYSTEM {
-		chatCompletionReq.System = chatCompletionReq.Messages[0].Content
-		chatCompletionReq.Messages = chatCompletionReq.Messages[1:]
-	}
-
-	if request.User != "" {
-		chatCompletionReq.Metadata = &model.Metadata{
-			UserId: request.User,
-		}
-	}
-
-	if chatCompletionReq.MaxTokens == 0 {
-		chatCompletionReq.MaxTokens = 4096
-	}
-
-	for _, message := range messages {
-
-		if contents, ok := message.Content.([]interface{}); ok {
-
-			for _, value := range contents {
-
-				if content, ok := value.(map[string]interface{}); ok {
-
-					if content["type"] == "image_url" {
-
-						if imageUrl, ok := content["image_url"].(map[string]interface{}); ok {
-
-							mimeType, data := common.GetMime(gconv.String(imageUrl["url"]))
-
-							content["source"] = model.Source{
-								Type:      "base64",
-								MediaType: mimeType,
-								Data:      data,
-							}
-
-							content["type"] = "image"
-							delete(content, "image_url")
-						}
-					}
-				}
-			}
-		}
-	}
+	chatCompletionReq := c.convChatCompletionReq(request)
 
 	if c.isGcp {
 		chatCompletionReq.Model = ""
@@ -308,6 +152,8 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 			}()
 
 			var id string
+			// 按内容块索引累积的思考内容与签名
+			thinkingBlocks := make(map[int]*model.ThinkingBlock)
 
 			for {
 
@@ -416,12 +262,44 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 					}
 				}
 
+				thinkingBlock := accumulateThinking(thinkingBlocks, chatCompletionRes)
+
 				if chatCompletionRes.Delta.StopReason != "" {
 					response.Choices = append(response.Choices, model.ChatCompletionChoice{
 						FinishReason: openai.FinishReasonStop,
 					})
 				} else {
-					if chatCompletionRes.Delta.Type == consts.DELTA_TYPE_INPUT_JSON {
+					if chatCompletionRes.Delta.Type == consts.DELTA_TYPE_THINKING {
+						response.Choices = append(response.Choices, model.ChatCompletionChoice{
+							Delta: &model.ChatCompletionStreamChoiceDelta{
+								Role:             consts.ROLE_ASSISTANT,
+								ReasoningContent: chatCompletionRes.Delta.Thinking,
+							},
+						})
+					} else if chatCompletionRes.Delta.Type == consts.DELTA_TYPE_SIGNATURE {
+						response.Choices = append(response.Choices, model.ChatCompletionChoice{
+							Delta: &model.ChatCompletionStreamChoiceDelta{
+								Role: consts.ROLE_ASSISTANT,
+							},
+						})
+					} else if thinkingBlock != nil {
+						response.Choices = append(response.Choices, model.ChatCompletionChoice{
+							Delta: &model.ChatCompletionStreamChoiceDelta{
+								Role:           consts.ROLE_ASSISTANT,
+								ThinkingBlocks: []model.ThinkingBlock{*thinkingBlock},
+							},
+						})
+					} else if chatCompletionRes.ContentBlock.Type == consts.CONTENT_TYPE_REDACTED_THINKING {
+						response.Choices = append(response.Choices, model.ChatCompletionChoice{
+							Delta: &model.ChatCompletionStreamChoiceDelta{
+								Role: consts.ROLE_ASSISTANT,
+								ThinkingBlocks: []model.ThinkingBlock{{
+									Type: consts.CONTENT_TYPE_REDACTED_THINKING,
+									Data: chatCompletionRes.ContentBlock.Data,
+								}},
+							},
+						})
+					} else if chatCompletionRes.Delta.Type == consts.DELTA_TYPE_INPUT_JSON {
 						response.Choices = append(response.Choices, model.ChatCompletionChoice{
 							Delta: &model.ChatCompletionStreamChoiceDelta{
 								Role: consts.ROLE_ASSISTANT,
@@ -503,6 +381,9 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 
 			var id string
 			var promptTokens int
+			var cacheCreationInputTokens, cacheReadInputTokens int
+			// 按内容块索引累积的思考内容与签名
+			thinkingBlocks := make(map[int]*model.ThinkingBlock)
 
 			for {
 
@@ -572,30 +453,71 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 					if chatCompletionRes.Usage.InputTokens != 0 {
 						promptTokens = chatCompletionRes.Usage.InputTokens
 					}
+					// message_delta中可能不包含缓存用量, 沿用message_start中的值
+					if chatCompletionRes.Usage.CacheCreationInputTokens != 0 {
+						cacheCreationInputTokens = chatCompletionRes.Usage.CacheCreationInputTokens
+					}
+					if chatCompletionRes.Usage.CacheReadInputTokens != 0 {
+						cacheReadInputTokens = chatCompletionRes.Usage.CacheReadInputTokens
+					}
 					response.Usage = &model.Usage{
 						PromptTokens:             promptTokens,
 						CompletionTokens:         chatCompletionRes.Usage.OutputTokens,
 						TotalTokens:              promptTokens + chatCompletionRes.Usage.OutputTokens,
-						CacheCreationInputTokens: chatCompletionRes.Usage.CacheCreationInputTokens,
-						CacheReadInputTokens:     chatCompletionRes.Usage.CacheReadInputTokens,
+						CacheCreationInputTokens: cacheCreationInputTokens,
+						CacheReadInputTokens:     cacheReadInputTokens,
 					}
 				}
 
 				if chatCompletionRes.Message.Usage != nil {
 					promptTokens = chatCompletionRes.Message.Usage.InputTokens
+					cacheCreationInputTokens = chatCompletionRes.Message.Usage.CacheCreationInputTokens
+					cacheReadInputTokens = chatCompletionRes.Message.Usage.CacheReadInputTokens
 					response.Usage = &model.Usage{
 						PromptTokens:             promptTokens,
-						CacheCreationInputTokens: chatCompletionRes.Message.Usage.CacheCreationInputTokens,
-						CacheReadInputTokens:     chatCompletionRes.Message.Usage.CacheReadInputTokens,
+						CacheCreationInputTokens: cacheCreationInputTokens,
+						CacheReadInputTokens:     cacheReadInputTokens,
 					}
 				}
 
+				thinkingBlock := accumulateThinking(thinkingBlocks, chatCompletionRes)
+
 				if chatCompletionRes.Delta.StopReason != "" {
 					response.Choices = append(response.Choices, model.ChatCompletionChoice{
 						FinishReason: openai.FinishReasonStop,
 					})
 				} else {
-					if chatCompletionRes.Delta.Type == consts.DELTA_TYPE_INPUT_JSON {
+					if chatCompletionRes.Delta.Type == consts.DELTA_TYPE_THINKING {
+						response.Choices = append(response.Choices, model.ChatCompletionChoice{
+							Delta: &model.ChatCompletionStreamChoiceDelta{
+								Role:             consts.ROLE_ASSISTANT,
+								ReasoningContent: chatCompletionRes.Delta.Thinking,
+							},
+						})
+					} else if chatCompletionRes.Delta.Type == consts.DELTA_TYPE_SIGNATURE {
+						response.Choices = append(response.Choices, model.ChatCompletionChoice{
+							Delta: &model.ChatCompletionStreamChoiceDelta{
+								Role: consts.ROLE_ASSISTANT,
+							},
+						})
+					} else if thinkingBlock != nil {
+						response.Choices = append(response.Choices, model.ChatCompletionChoice{
+							Delta: &model.ChatCompletionStreamChoiceDelta{
+								Role:           consts.ROLE_ASSISTANT,
+								ThinkingBlocks: []model.ThinkingBlock{*thinkingBlock},
+							},
+						})
+					} else if chatCompletionRes.ContentBlock.Type == consts.CONTENT_TYPE_REDACTED_THINKING {
+						response.Choices = append(response.Choices, model.ChatCompletionChoice{
+							Delta: &model.ChatCompletionStreamChoiceDelta{
+								Role: consts.ROLE_ASSISTANT,
+								ThinkingBlocks: []model.ThinkingBlock{{
+									Type: consts.CONTENT_TYPE_REDACTED_THINKING,
+									Data: chatCompletionRes.ContentBlock.Data,
+								}},
+							},
+						})
+					} else if chatCompletionRes.Delta.Type == consts.DELTA_TYPE_INPUT_JSON {
 						response.Choices = append(response.Choices, model.ChatCompletionChoice{
 							Delta: &model.ChatCompletionStreamChoiceDelta{
 								Role: consts.ROLE_ASSISTANT,
@@ -655,3 +577,507 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 
 	return responseChan, nil
 }
+
+// 累积thinking_delta与signature_delta, 在content_block_stop时返回完整的思考块, 以便客户端原样回传
+func accumulateThinking(thinkingBlocks map[int]*model.ThinkingBlock, chatCompletionRes *model.AnthropicChatCompletionRes) *model.ThinkingBlock {
+
+	switch {
+	case chatCompletionRes.Delta.Type == consts.DELTA_TYPE_THINKING || chatCompletionRes.Delta.Type == consts.DELTA_TYPE_SIGNATURE:
+
+		thinkingBlock, ok := thinkingBlocks[chatCompletionRes.Index]
+		if !ok {
+			thinkingBlock = &model.ThinkingBlock{Type: consts.CONTENT_TYPE_THINKING}
+			thinkingBlocks[chatCompletionRes.Index] = thinkingBlock
+		}
+
+		thinkingBlock.Thinking += chatCompletionRes.Delta.Thinking
+		thinkingBlock.Signature += chatCompletionRes.Delta.Signature
+
+	case chatCompletionRes.Type == consts.EVENT_TYPE_CONTENT_BLOCK_STOP:
+
+		if thinkingBlock, ok := thinkingBlocks[chatCompletionRes.Index]; ok {
+			delete(thinkingBlocks, chatCompletionRes.Index)
+			return thinkingBlock
+		}
+	}
+
+	return nil
+}
+
+// 转换为Anthropic的请求参数
+func (c *Client) convChatCompletionReq(request model.ChatCompletionRequest) model.AnthropicChatCompletionReq {
+
+	isSupportSystemRole := true
+	if c.isSupportSystemRole != nil {
+		isSupportSystemRole = *c.isSupportSystemRole
+	}
+
+	system, messages := convMessages(request.Messages, isSupportSystemRole)
+
+	chatCompletionReq := model.AnthropicChatCompletionReq{
+		Model:         request.Model,
+		System:        system,
+		Messages:      messages,
+		MaxTokens:     request.MaxTokens,
+		StopSequences: request.Stop,
+		Stream:        request.Stream,
+		Temperature:   request.Temperature,
+		ToolChoice:    request.ToolChoice,
+		TopK:          request.TopK,
+		TopP:          request.TopP,
+		Tools:         convTools(request.Tools),
+	}
+
+	if request.User != "" {
+		chatCompletionReq.Metadata = &model.Metadata{
+			UserId: request.User,
+		}
+	}
+
+	if chatCompletionReq.MaxTokens == 0 {
+		chatCompletionReq.MaxTokens = 4096
+	}
+
+	if chatCompletionReq.Thinking = convThinking(request); chatCompletionReq.Thinking != nil && chatCompletionReq.Thinking.Type == "enabled" {
+
+		if chatCompletionReq.MaxTokens <= chatCompletionReq.Thinking.BudgetTokens {
+			chatCompletionReq.MaxTokens = chatCompletionReq.Thinking.BudgetTokens + 4096
+		}
+
+		// 开启思考时不支持修改temperature、top_k、top_p
+		chatCompletionReq.Temperature = 0
+		chatCompletionReq.TopK = 0
+		chatCompletionReq.TopP = 0
+	}
+
+	for _, message := range messages {
+
+		if contents, ok := message.Content.([]interface{}); ok {
+
+			for _, value := range contents {
+
+				if content, ok := value.(map[string]interface{}); ok {
+
+					if content["type"] == "image_url" {
+
+						if imageUrl, ok := content["image_url"].(map[string]interface{}); ok {
+
+							mimeType, data := common.GetMime(gconv.String(imageUrl["url"]))
+
+							content["source"] = model.Source{
+								Type:      "base64",
+								MediaType: mimeType,
+								Data:      data,
+							}
+
+							content["type"] = "image"
+							delete(content, "image_url")
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if request.AutoPromptCaching {
+		autoPromptCaching(&chatCompletionReq)
+	}
+
+	return chatCompletionReq
+}
+
+// 转换为OpenAI格式的响应
+func convChatCompletionRes(modelName string, chatCompletionRes *model.AnthropicChatCompletionRes) model.ChatCompletionResponse {
+
+	res := model.ChatCompletionResponse{
+		ID:      consts.COMPLETION_ID_PREFIX + chatCompletionRes.Id,
+		Object:  consts.COMPLETION_OBJECT,
+		Created: gtime.Timestamp(),
+		Model:   modelName,
+		Usage: &model.Usage{
+			PromptTokens:             chatCompletionRes.Usage.InputTokens,
+			CompletionTokens:         chatCompletionRes.Usage.OutputTokens,
+			TotalTokens:              chatCompletionRes.Usage.InputTokens + chatCompletionRes.Usage.OutputTokens,
+			CacheCreationInputTokens: chatCompletionRes.Usage.CacheCreationInputTokens,
+			CacheReadInputTokens:     chatCompletionRes.Usage.CacheReadInputTokens,
+		},
+	}
+
+	var (
+		reasoningContent string
+		thinkingBlocks   []model.ThinkingBlock
+		toolCalls        []openai.ToolCall
+	)
+
+	for _, content := range chatCompletionRes.Content {
+		if content.Type == consts.CONTENT_TYPE_THINKING || content.Type == consts.CONTENT_TYPE_REDACTED_THINKING {
+			reasoningContent += content.Thinking
+			thinkingBlocks = append(thinkingBlocks, model.ThinkingBlock{
+				Type:      content.Type,
+				Thinking:  content.Thinking,
+				Signature: content.Signature,
+				Data:      content.Data,
+			})
+		} else if content.Type == consts.CONTENT_TYPE_TOOL_USE {
+			toolCalls = append(toolCalls, openai.ToolCall{
+				ID:   content.Id,
+				Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name:      content.Name,
+					Arguments: gjson.MustEncodeString(content.Input),
+				},
+			})
+		} else if content.Type == consts.DELTA_TYPE_INPUT_JSON {
+			res.Choices = append(res.Choices, model.ChatCompletionChoice{
+				Delta: &model.ChatCompletionStreamChoiceDelta{
+					Role: consts.ROLE_ASSISTANT,
+					ToolCalls: []openai.ToolCall{{
+						Function: openai.FunctionCall{
+							Arguments: content.PartialJson,
+						},
+					}},
+				},
+			})
+		} else {
+			res.Choices = append(res.Choices, model.ChatCompletionChoice{
+				Message: &model.ChatCompletionMessage{
+					Role:    chatCompletionRes.Role,
+					Content: content.Text,
+				},
+				FinishReason: "stop",
+			})
+		}
+	}
+
+	if len(thinkingBlocks) > 0 || len(toolCalls) > 0 {
+
+		var message *model.ChatCompletionMessage
+		for _, choice := range res.Choices {
+			if choice.Message != nil {
+				message = choice.Message
+				break
+			}
+		}
+
+		if message == nil {
+			message = &model.ChatCompletionMessage{
+				Role:    consts.ROLE_ASSISTANT,
+				Content: "",
+			}
+			res.Choices = append(res.Choices, model.ChatCompletionChoice{
+				Message:      message,
+				FinishReason: openai.FinishReasonStop,
+			})
+		}
+
+		if reasoningContent != "" {
+			message.ReasoningContent = reasoningContent
+		}
+
+		message.ThinkingBlocks = thinkingBlocks
+
+		if len(toolCalls) > 0 {
+			message.ToolCalls = toolCalls
+			for i := range res.Choices {
+				if res.Choices[i].Message == message {
+					res.Choices[i].FinishReason = openai.FinishReasonToolCalls
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// 转换为Anthropic的消息列表, 不按位置改写角色, 带tool_calls或思考块的assistant消息即使content为空也保留,
+// 工具结果转换为user消息中的tool_result块, 连续的工具结果合并为同一条user消息
+func convMessages(messages []model.ChatCompletionMessage, isSupportSystemRole bool) (system any, anthropicMessages []model.ChatCompletionMessage) {
+
+	anthropicMessages = make([]model.ChatCompletionMessage, 0, len(messages))
+
+	isToolResult := false
+
+	for _, message := range messages {
+
+		isEmpty := message.Content == nil || message.Content == ""
+
+		if isEmpty && len(message.ToolCalls) == 0 && len(message.ThinkingBlocks) == 0 && message.ToolCallID == "" {
+			continue
+		}
+
+		if message.Role == consts.ROLE_SYSTEM {
+
+			if isSupportSystemRole && system == nil && len(anthropicMessages) == 0 {
+
+				system = message.Content
+
+				if message.CacheControl != nil {
+					system = setCacheControl(message.Content, message.CacheControl)
+				}
+
+				continue
+			}
+
+			message.Role = consts.ROLE_USER
+		}
+
+		if message.Role == consts.ROLE_TOOL || message.Role == consts.ROLE_FUNCTION {
+			message.Role = consts.ROLE_USER
+		}
+
+		anthropicMessage := convMessage(message)
+
+		if message.ToolCallID != "" {
+
+			// 多个工具结果需在同一条user消息中返回
+			if isToolResult {
+				last := &anthropicMessages[len(anthropicMessages)-1]
+				last.Content = append(last.Content.([]interface{}), anthropicMessage.Content.([]interface{})...)
+				continue
+			}
+
+			isToolResult = true
+
+		} else {
+			isToolResult = false
+		}
+
+		anthropicMessages = append(anthropicMessages, anthropicMessage)
+	}
+
+	return system, anthropicMessages
+}
+
+// 转换为Anthropic的消息格式, 思考块需原样回传并位于tool_use之前, 否则多轮工具调用会报错
+func convMessage(message model.ChatCompletionMessage) model.ChatCompletionMessage {
+
+	if len(message.ThinkingBlocks) == 0 && len(message.ToolCalls) == 0 && message.ToolCallID == "" {
+
+		message.ReasoningContent = nil
+
+		if message.CacheControl != nil {
+			message.Content = setCacheControl(message.Content, message.CacheControl)
+			message.CacheControl = nil
+		}
+
+		return message
+	}
+
+	contents := make([]interface{}, 0)
+
+	if message.ToolCallID != "" {
+
+		contents = append(contents, map[string]interface{}{
+			"type":        consts.CONTENT_TYPE_TOOL_RESULT,
+			"tool_use_id": message.ToolCallID,
+			"content":     message.Content,
+		})
+
+		return model.ChatCompletionMessage{
+			Role:    consts.ROLE_USER,
+			Content: setCacheControl(contents, message.CacheControl),
+		}
+	}
+
+	for _, thinkingBlock := range message.ThinkingBlocks {
+		contents = append(contents, thinkingBlock)
+	}
+
+	if values, ok := message.Content.([]interface{}); ok {
+		contents = append(contents, values...)
+	} else if text := gconv.String(message.Content); text != "" {
+		contents = append(contents, map[string]interface{}{
+			"type": consts.CONTENT_TYPE_TEXT,
+			"text": text,
+		})
+	}
+
+	for _, toolCall := range message.ToolCalls {
+
+		input := make(map[string]interface{})
+		if toolCall.Function.Arguments != "" {
+			if err := gjson.Unmarshal([]byte(toolCall.Function.Arguments), &input); err != nil {
+				input = make(map[string]interface{})
+			}
+		}
+
+		contents = append(contents, map[string]interface{}{
+			"type":  consts.CONTENT_TYPE_TOOL_USE,
+			"id":    toolCall.ID,
+			"name":  toolCall.Function.Name,
+			"input": input,
+		})
+	}
+
+	return model.ChatCompletionMessage{
+		Role:    message.Role,
+		Content: setCacheControl(contents, message.CacheControl),
+	}
+}
+
+func convThinking(request model.ChatCompletionRequest) *model.AnthropicThinking {
+
+	if request.Thinking != nil {
+		return request.Thinking
+	}
+
+	var budgetTokens int
+
+	switch request.ReasoningEffort {
+	case "low":
+		budgetTokens = 1024
+	case "medium":
+		budgetTokens = 4096
+	case "high":
+		budgetTokens = 16384
+	default:
+		return nil
+	}
+
+	return &model.AnthropicThinking{
+		Type:         "enabled",
+		BudgetTokens: budgetTokens,
+	}
+}
+
+// 转换为Anthropic的工具格式, 工具上的cache_control会被保留, 非function类型的工具原样透传
+func convTools(tools any) any {
+
+	if tools == nil {
+		return nil
+	}
+
+	values := make([]interface{}, 0)
+	if err := gjson.Unmarshal(gjson.MustEncode(tools), &values); err != nil {
+		return tools
+	}
+
+	for i, value := range values {
+
+		tool, ok := value.(map[string]interface{})
+		if !ok || tool["type"] != "function" {
+			continue
+		}
+
+		function, ok := tool["function"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		anthropicTool := map[string]interface{}{
+			"name":         function["name"],
+			"input_schema": function["parameters"],
+		}
+
+		if description, ok := function["description"]; ok {
+			anthropicTool["description"] = description
+		}
+
+		if anthropicTool["input_schema"] == nil {
+			anthropicTool["input_schema"] = map[string]interface{}{"type": "object"}
+		}
+
+		if cacheControl, ok := tool["cache_control"]; ok {
+			anthropicTool["cache_control"] = cacheControl
+		} else if cacheControl, ok := function["cache_control"]; ok {
+			anthropicTool["cache_control"] = cacheControl
+		}
+
+		values[i] = anthropicTool
+	}
+
+	return values
+}
+
+// 在内容的最后一个块上设置缓存断点, 字符串内容会被转换为text块
+func setCacheControl(content any, cacheControl *model.CacheControl) any {
+
+	if cacheControl == nil {
+		return content
+	}
+
+	if values, ok := content.([]interface{}); ok {
+
+		// 复制切片与被修改的块, 避免断点写入调用方复用的消息历史
+		contents := make([]interface{}, len(values))
+		copy(contents, values)
+
+		for i := len(contents) - 1; i >= 0; i-- {
+			if block, ok := contents[i].(map[string]interface{}); ok {
+
+				newBlock := make(map[string]interface{}, len(block)+1)
+				for key, value := range block {
+					newBlock[key] = value
+				}
+
+				newBlock["cache_control"] = cacheControl
+				contents[i] = newBlock
+
+				break
+			}
+		}
+
+		return contents
+	}
+
+	if text, ok := content.(string); ok && text != "" {
+		return []interface{}{map[string]interface{}{
+			"type":          consts.CONTENT_TYPE_TEXT,
+			"text":          text,
+			"cache_control": cacheControl,
+		}}
+	}
+
+	return content
+}
+
+// 统计内容中带缓存断点的块数
+func countCacheControl(content any) int {
+
+	contents, ok := content.([]interface{})
+	if !ok {
+		return 0
+	}
+
+	count := 0
+	for _, value := range contents {
+		if block, ok := value.(map[string]interface{}); ok && block["cache_control"] != nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+// 自动设置缓存断点, 依次缓存system、tools及除最后一轮用户输入之外的最长稳定前缀, 总数不超过Anthropic允许的4个
+func autoPromptCaching(chatCompletionReq *model.AnthropicChatCompletionReq) {
+
+	breakpoints := countCacheControl(chatCompletionReq.System) + countCacheControl(chatCompletionReq.Tools)
+
+	for _, message := range chatCompletionReq.Messages {
+		breakpoints += countCacheControl(message.Content)
+	}
+
+	cacheControl := &model.CacheControl{Type: "ephemeral"}
+
+	if breakpoints < maxCacheBreakpoints && chatCompletionReq.System != nil && countCacheControl(chatCompletionReq.System) == 0 {
+		chatCompletionReq.System = setCacheControl(chatCompletionReq.System, cacheControl)
+		breakpoints++
+	}
+
+	if breakpoints < maxCacheBreakpoints && chatCompletionReq.Tools != nil && countCacheControl(chatCompletionReq.Tools) == 0 {
+		chatCompletionReq.Tools = setCacheControl(chatCompletionReq.Tools, cacheControl)
+		breakpoints++
+	}
+
+	// 倒数第二条用户消息之前的内容在多轮对话中保持不变, 最后一条消息用于写入缓存供下一轮读取
+	for _, i := range []int{len(chatCompletionReq.Messages) - 3, len(chatCompletionReq.Messages) - 1} {
+
+		if breakpoints >= maxCacheBreakpoints || i < 0 || countCacheControl(chatCompletionReq.Messages[i].Content) > 0 {
+			continue
+		}
+
+		chatCompletionReq.Messages[i].Content = setCacheControl(chatCompletionReq.Messages[i].Content, cacheControl)
+		breakpoints++
+	}
+}
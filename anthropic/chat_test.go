@@ -0,0 +1,255 @@
+package anthropic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/iimeta/fastapi-sdk/consts"
+	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/go-openai"
+)
+
+func TestConvChatCompletionReqToolRoundTrip(t *testing.T) {
+
+	client := NewClient(context.Background(), "claude-3-7-sonnet-20250219", "sk-test", "", "", nil)
+
+	request := model.ChatCompletionRequest{
+		Model: "claude-3-7-sonnet-20250219",
+		Messages: []model.ChatCompletionMessage{
+			{Role: consts.ROLE_SYSTEM, Content: "You are a helpful assistant."},
+			{Role: consts.ROLE_USER, Content: "What's the weather in Beijing and Shanghai?"},
+			{
+				Role: consts.ROLE_ASSISTANT,
+				ThinkingBlocks: []model.ThinkingBlock{
+					{Type: consts.CONTENT_TYPE_THINKING, Thinking: "I should call get_weather twice.", Signature: "sig"},
+				},
+				ToolCalls: []openai.ToolCall{
+					{ID: "toolu_1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Beijing"}`}},
+					{ID: "toolu_2", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Shanghai"}`}},
+				},
+			},
+			{Role: consts.ROLE_TOOL, ToolCallID: "toolu_1", Content: "sunny"},
+			{Role: consts.ROLE_TOOL, ToolCallID: "toolu_2", Content: "rainy"},
+		},
+	}
+
+	chatCompletionReq := client.convChatCompletionReq(request)
+
+	if chatCompletionReq.System != "You are a helpful assistant." {
+		t.Fatalf("system = %v", chatCompletionReq.System)
+	}
+
+	messages := chatCompletionReq.Messages
+	if len(messages) != 3 {
+		t.Fatalf("len(messages) = %d, want 3", len(messages))
+	}
+
+	if messages[0].Role != consts.ROLE_USER || messages[0].Content != "What's the weather in Beijing and Shanghai?" {
+		t.Fatalf("messages[0] = %+v", messages[0])
+	}
+
+	if messages[1].Role != consts.ROLE_ASSISTANT {
+		t.Fatalf("messages[1].Role = %s, want assistant", messages[1].Role)
+	}
+
+	assistantContents, ok := messages[1].Content.([]interface{})
+	if !ok || len(assistantContents) != 3 {
+		t.Fatalf("messages[1].Content = %+v", messages[1].Content)
+	}
+
+	if thinkingBlock, ok := assistantContents[0].(model.ThinkingBlock); !ok || thinkingBlock.Signature != "sig" {
+		t.Fatalf("assistant content[0] = %+v, want thinking block", assistantContents[0])
+	}
+
+	for i, id := range []string{"toolu_1", "toolu_2"} {
+		toolUse := assistantContents[i+1].(map[string]interface{})
+		if toolUse["type"] != consts.CONTENT_TYPE_TOOL_USE || toolUse["id"] != id {
+			t.Fatalf("assistant content[%d] = %+v, want tool_use %s", i+1, toolUse, id)
+		}
+	}
+
+	if messages[2].Role != consts.ROLE_USER {
+		t.Fatalf("messages[2].Role = %s, want user", messages[2].Role)
+	}
+
+	toolResults, ok := messages[2].Content.([]interface{})
+	if !ok || len(toolResults) != 2 {
+		t.Fatalf("messages[2].Content = %+v", messages[2].Content)
+	}
+
+	for i, id := range []string{"toolu_1", "toolu_2"} {
+		toolResult := toolResults[i].(map[string]interface{})
+		if toolResult["type"] != consts.CONTENT_TYPE_TOOL_RESULT || toolResult["tool_use_id"] != id {
+			t.Fatalf("tool result[%d] = %+v, want tool_result %s", i, toolResult, id)
+		}
+	}
+}
+
+func TestAutoPromptCaching(t *testing.T) {
+
+	textBlock := func(text string, isCached bool) map[string]interface{} {
+
+		block := map[string]interface{}{"type": consts.CONTENT_TYPE_TEXT, "text": text}
+		if isCached {
+			block["cache_control"] = &model.CacheControl{Type: "ephemeral"}
+		}
+
+		return block
+	}
+
+	tools := []interface{}{map[string]interface{}{"name": "get_weather", "input_schema": map[string]interface{}{"type": "object"}}}
+
+	tests := []struct {
+		name              string
+		chatCompletionReq model.AnthropicChatCompletionReq
+		wantSystem        int
+		wantTools         int
+		wantMessages      []int
+	}{
+		{
+			name: "system, tools and stable prefix",
+			chatCompletionReq: model.AnthropicChatCompletionReq{
+				System: "You are a helpful assistant.",
+				Tools:  tools,
+				Messages: []model.ChatCompletionMessage{
+					{Role: consts.ROLE_USER, Content: "1"},
+					{Role: consts.ROLE_ASSISTANT, Content: "2"},
+					{Role: consts.ROLE_USER, Content: "3"},
+				},
+			},
+			wantSystem:   1,
+			wantTools:    1,
+			wantMessages: []int{1, 0, 1},
+		},
+		{
+			name: "existing breakpoints in one message are counted individually",
+			chatCompletionReq: model.AnthropicChatCompletionReq{
+				System: "You are a helpful assistant.",
+				Tools:  tools,
+				Messages: []model.ChatCompletionMessage{
+					{Role: consts.ROLE_USER, Content: []interface{}{textBlock("a", true), textBlock("b", true), textBlock("c", true)}},
+					{Role: consts.ROLE_ASSISTANT, Content: "2"},
+					{Role: consts.ROLE_USER, Content: "3"},
+				},
+			},
+			wantSystem:   1,
+			wantTools:    0,
+			wantMessages: []int{3, 0, 0},
+		},
+		{
+			name: "limit already reached",
+			chatCompletionReq: model.AnthropicChatCompletionReq{
+				System: []interface{}{textBlock("a", true), textBlock("b", true)},
+				Tools:  tools,
+				Messages: []model.ChatCompletionMessage{
+					{Role: consts.ROLE_USER, Content: []interface{}{textBlock("c", true), textBlock("d", true)}},
+				},
+			},
+			wantSystem:   2,
+			wantTools:    0,
+			wantMessages: []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			chatCompletionReq := tt.chatCompletionReq
+			chatCompletionReq.Messages = append([]model.ChatCompletionMessage(nil), tt.chatCompletionReq.Messages...)
+
+			autoPromptCaching(&chatCompletionReq)
+
+			if got := countCacheControl(chatCompletionReq.System); got != tt.wantSystem {
+				t.Errorf("system breakpoints = %d, want %d", got, tt.wantSystem)
+			}
+
+			if got := countCacheControl(chatCompletionReq.Tools); got != tt.wantTools {
+				t.Errorf("tools breakpoints = %d, want %d", got, tt.wantTools)
+			}
+
+			for i, want := range tt.wantMessages {
+				if got := countCacheControl(chatCompletionReq.Messages[i].Content); got != want {
+					t.Errorf("messages[%d] breakpoints = %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+
+	// 断点不能写入调用方的原始内容
+	if got := countCacheControl(tools); got != 0 {
+		t.Errorf("caller tools breakpoints = %d, want 0", got)
+	}
+}
+
+func TestConvChatCompletionReqDoesNotMutateMessages(t *testing.T) {
+
+	client := NewClient(context.Background(), "claude-3-7-sonnet-20250219", "sk-test", "", "", nil)
+
+	history := []model.ChatCompletionMessage{
+		{Role: consts.ROLE_USER, Content: []interface{}{map[string]interface{}{"type": consts.CONTENT_TYPE_TEXT, "text": "1"}}},
+		{Role: consts.ROLE_ASSISTANT, Content: []interface{}{map[string]interface{}{"type": consts.CONTENT_TYPE_TEXT, "text": "2"}}},
+		{Role: consts.ROLE_USER, Content: []interface{}{map[string]interface{}{"type": consts.CONTENT_TYPE_TEXT, "text": "3"}}},
+	}
+
+	for turn := 0; turn < 3; turn++ {
+
+		chatCompletionReq := client.convChatCompletionReq(model.ChatCompletionRequest{
+			Model:             "claude-3-7-sonnet-20250219",
+			Messages:          history,
+			AutoPromptCaching: true,
+		})
+
+		breakpoints := 0
+		for _, message := range chatCompletionReq.Messages {
+			breakpoints += countCacheControl(message.Content)
+		}
+
+		if breakpoints != 2 {
+			t.Fatalf("turn %d breakpoints = %d, want 2", turn, breakpoints)
+		}
+	}
+
+	for i, message := range history {
+		if got := countCacheControl(message.Content); got != 0 {
+			t.Errorf("history[%d] breakpoints = %d, want 0", i, got)
+		}
+	}
+}
+
+func TestAccumulateThinking(t *testing.T) {
+
+	events := []string{
+		`{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Let me "}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"check."}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig"}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+		`{"type":"content_block_stop","index":1}`,
+	}
+
+	thinkingBlocks := make(map[int]*model.ThinkingBlock)
+
+	var completed []model.ThinkingBlock
+	for _, event := range events {
+
+		chatCompletionRes := new(model.AnthropicChatCompletionRes)
+		if err := gjson.Unmarshal([]byte(event), chatCompletionRes); err != nil {
+			t.Fatal(err)
+		}
+
+		if thinkingBlock := accumulateThinking(thinkingBlocks, chatCompletionRes); thinkingBlock != nil {
+			completed = append(completed, *thinkingBlock)
+		}
+	}
+
+	want := model.ThinkingBlock{Type: consts.CONTENT_TYPE_THINKING, Thinking: "Let me check.", Signature: "sig"}
+	if len(completed) != 1 || completed[0] != want {
+		t.Fatalf("completed = %+v, want [%+v]", completed, want)
+	}
+
+	if len(thinkingBlocks) != 0 {
+		t.Fatalf("thinkingBlocks = %+v, want empty", thinkingBlocks)
+	}
+}
@@ -3,9 +3,17 @@ package anthropic
 import (
 	"context"
 	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
 )
 
 func (c *Client) Image(ctx context.Context, request model.ImageRequest) (res model.ImageResponse, err error) {
-	//TODO implement me
-	panic("implement me")
+	return res, sdkerr.NewApiError(400, "unsupported_operation", "Anthropic does not support image generation.", "invalid_request_error", "")
+}
+
+func (c *Client) ImageEdit(ctx context.Context, request model.ImageEditRequest) (res model.ImageResponse, err error) {
+	return res, sdkerr.NewApiError(400, "unsupported_operation", "Anthropic does not support image edit.", "invalid_request_error", "")
+}
+
+func (c *Client) ImageVariation(ctx context.Context, request model.ImageVariationRequest) (res model.ImageResponse, err error) {
+	return res, sdkerr.NewApiError(400, "unsupported_operation", "Anthropic does not support image variation.", "invalid_request_error", "")
 }
@@ -0,0 +1,209 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gogf/gf/v2/os/grpool"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/iimeta/fastapi-sdk/logger"
+	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/util"
+	"github.com/iimeta/go-openai"
+	"io"
+	"math"
+	"os"
+	"sync"
+)
+
+// LongTranscription 长音频转录, 将音频切分后并发转录, 再按分片偏移修正时间戳并合并结果
+// 仅支持16位PCM的WAV音频切分, mp3、m4a、ogg等压缩格式不会被切分, 不超过MaxChunkSize时直接转录, 超过时返回错误, 需先转码为WAV
+// 任一分片失败时取消其余分片并返回该错误
+// 为获取时间戳, 分片统一使用verbose_json格式请求
+func LongTranscription(ctx context.Context, client Client, request model.AudioRequest, options model.LongAudioOptions) (res model.AudioResponse, err error) {
+
+	logger.Infof(ctx, "LongTranscription model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "LongTranscription model: %s totalTime: %d ms", request.Model, res.TotalTime)
+	}()
+
+	if options.MaxChunkDuration <= 0 {
+		options.MaxChunkDuration = 600
+	}
+
+	if options.MaxChunkSize <= 0 {
+		options.MaxChunkSize = 24 * 1024 * 1024
+	}
+
+	if options.SilenceThreshold <= 0 {
+		options.SilenceThreshold = 0.01
+	}
+
+	if options.MinSilenceDuration <= 0 {
+		options.MinSilenceDuration = 0.3
+	}
+
+	if options.Concurrency <= 0 {
+		options.Concurrency = 4
+	}
+
+	var data []byte
+	if request.Reader != nil {
+		data, err = io.ReadAll(request.Reader)
+	} else {
+		data, err = os.ReadFile(request.FilePath)
+	}
+
+	if err != nil {
+		logger.Errorf(ctx, "LongTranscription model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	wav, err := util.ParseWav(data)
+	if err != nil {
+
+		if len(data) > options.MaxChunkSize {
+			err = errors.New(fmt.Sprintf("audio size %d exceeds %d and cannot be split: %v", len(data), options.MaxChunkSize, err))
+			logger.Errorf(ctx, "LongTranscription model: %s, error: %v", request.Model, err)
+			return res, err
+		}
+
+		request.Reader = bytes.NewReader(data)
+
+		if options.Translation {
+			return client.Translation(ctx, request)
+		}
+
+		return client.Transcription(ctx, request)
+	}
+
+	maxDuration := math.Min(options.MaxChunkDuration, float64(options.MaxChunkSize-44)/float64(wav.ByteRate()))
+	chunks := wav.Split(maxDuration, options.SplitOnSilence, options.SilenceThreshold, options.MinSilenceDuration)
+
+	logger.Infof(ctx, "LongTranscription model: %s, duration: %f, chunks: %d", request.Model, wav.Duration(), len(chunks))
+
+	// 任一分片失败时取消尚未完成的分片
+	chunkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		results    = make([]model.AudioResponse, len(chunks))
+		pool       = grpool.New(options.Concurrency)
+		wg         sync.WaitGroup
+		errOnce    sync.Once
+		chunkErr   error
+		chunkIndex int
+	)
+
+	defer pool.Close()
+
+	fail := func(i int, err error) {
+		errOnce.Do(func() {
+			chunkErr = err
+			chunkIndex = i
+			cancel()
+		})
+	}
+
+	for i, chunk := range chunks {
+
+		chunkRequest := request
+		chunkRequest.FilePath = fmt.Sprintf("chunk_%d.wav", i)
+		chunkRequest.Reader = bytes.NewReader(chunk.Data)
+		chunkRequest.Format = openai.AudioResponseFormatVerboseJSON
+
+		wg.Add(1)
+
+		if err = pool.AddWithRecover(chunkCtx, func(ctx context.Context) {
+
+			defer wg.Done()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			var err error
+			if options.Translation {
+				results[i], err = client.Translation(ctx, chunkRequest)
+			} else {
+				results[i], err = client.Transcription(ctx, chunkRequest)
+			}
+
+			if err != nil {
+				fail(i, err)
+			}
+
+		}, func(ctx context.Context, err error) {
+			fail(i, err)
+		}); err != nil {
+			wg.Done()
+			fail(i, err)
+		}
+	}
+
+	wg.Wait()
+
+	if chunkErr != nil {
+		logger.Errorf(ctx, "LongTranscription model: %s, chunk: %d, error: %v", request.Model, chunkIndex, chunkErr)
+		return res, chunkErr
+	}
+
+	// 调用方取消时未执行的分片没有结果
+	if err = ctx.Err(); err != nil {
+		logger.Errorf(ctx, "LongTranscription model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	res = mergeAudioResponses(chunks, results)
+
+	logger.Infof(ctx, "LongTranscription model: %s finished", request.Model)
+
+	return res, nil
+}
+
+// mergeAudioResponses 按分片偏移修正时间戳并合并结果
+func mergeAudioResponses(chunks []util.WavChunk, results []model.AudioResponse) (res model.AudioResponse) {
+
+	texts := make([]string, 0, len(results))
+
+	for i, result := range results {
+
+		offset := chunks[i].Offset
+
+		if res.Task == "" {
+			res.Task = result.Task
+		}
+
+		if res.Language == "" {
+			res.Language = result.Language
+		}
+
+		for _, segment := range result.Segments {
+			segment.ID = len(res.Segments)
+			segment.Start += offset
+			segment.End += offset
+			res.Segments = append(res.Segments, segment)
+		}
+
+		for _, word := range result.Words {
+			word.Start += offset
+			word.End += offset
+			res.Words = append(res.Words, word)
+		}
+
+		if text := gstr.Trim(result.Text); text != "" {
+			texts = append(texts, text)
+		}
+
+		res.Duration = offset + result.Duration
+	}
+
+	res.Text = gstr.Join(texts, " ")
+
+	return res
+}
@@ -0,0 +1,113 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/util"
+	"github.com/iimeta/go-openai"
+)
+
+// transcriptionClient 仅实现Transcription, 按分片内容中的首个采样值决定返回结果
+type transcriptionClient struct {
+	Client
+	calls atomic.Int32
+}
+
+func (c *transcriptionClient) Transcription(ctx context.Context, request model.AudioRequest) (res model.AudioResponse, err error) {
+
+	c.calls.Add(1)
+
+	data, err := io.ReadAll(request.Reader)
+	if err != nil {
+		return res, err
+	}
+
+	wav, err := util.ParseWav(data)
+	if err != nil {
+		return res, err
+	}
+
+	switch int16(binary.LittleEndian.Uint16(wav.Data)) {
+	case -1:
+		return res, errors.New("chunk failed")
+	case -2:
+		// 模拟耗时的分片, 等待被取消
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	return model.AudioResponse{
+		Text:     "ok",
+		Duration: wav.Duration(),
+	}, nil
+}
+
+// longWav 生成每秒一个分片的WAV, markers为每个分片首个采样的值
+func longWav(markers ...int16) []byte {
+
+	wav := &util.Wav{Channels: 1, SampleRate: 1000, BitsPerSample: 16}
+
+	pcm := make([]byte, len(markers)*2000)
+	for i, marker := range markers {
+		binary.LittleEndian.PutUint16(pcm[i*2000:], uint16(marker))
+	}
+
+	return wav.Encode(pcm)
+}
+
+func TestLongTranscription(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		markers     []int16
+		concurrency int
+		wantErr     bool
+		wantText    string
+		maxCalls    int32
+	}{
+		{name: "merge chunks", markers: []int16{0, 0, 0}, concurrency: 2, wantText: "ok ok ok", maxCalls: 3},
+		{name: "first error cancels running chunks", markers: []int16{-1, -2, -2}, concurrency: 3, wantErr: true, maxCalls: 3},
+		{name: "first error skips queued chunks", markers: []int16{-1, 0, 0, 0, 0, 0}, concurrency: 1, wantErr: true, maxCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			client := new(transcriptionClient)
+
+			start := time.Now()
+
+			res, err := LongTranscription(context.Background(), client, model.AudioRequest{
+				Model:  openai.Whisper1,
+				Reader: bytes.NewReader(longWav(tt.markers...)),
+			}, model.LongAudioOptions{MaxChunkDuration: 1, Concurrency: tt.concurrency})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LongTranscription() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if time.Since(start) > 3*time.Second {
+				t.Errorf("LongTranscription() took %s, running chunks were not cancelled", time.Since(start))
+			}
+
+			if calls := client.calls.Load(); calls > tt.maxCalls {
+				t.Errorf("calls = %d, want at most %d", calls, tt.maxCalls)
+			}
+
+			if !tt.wantErr && res.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", res.Text, tt.wantText)
+			}
+		})
+	}
+}
@@ -6,17 +6,26 @@ import (
 	"fmt"
 	"github.com/gogf/gf/v2/encoding/gjson"
 	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/text/gstr"
 	"github.com/iimeta/fastapi-sdk/logger"
-	"github.com/iimeta/fastapi-sdk/model"
 	"github.com/iimeta/fastapi-sdk/sdkerr"
 )
 
+const (
+	chatPath        = "/wenxinworkshop/chat/completions_pro"
+	qianfanChatPath = "/chat/completions"
+)
+
 type Client struct {
 	accessToken         string
+	apiKey              string
+	secretKey           string
 	baseURL             string
 	path                string
 	proxyURL            string
 	isSupportSystemRole *bool
+	header              map[string]string
+	isQianfan           bool
 }
 
 func NewClient(ctx context.Context, model, key, baseURL, path string, isSupportSystemRole *bool, proxyURL ...string) *Client {
@@ -26,10 +35,16 @@ func NewClient(ctx context.Context, model, key, baseURL, path string, isSupportS
 	client := &Client{
 		accessToken:         key,
 		baseURL:             "https://aip.baidubce.com/rpc/2.0/ai_custom/v1",
-		path:                "/wenxinworkshop/chat/completions_pro",
+		path:                chatPath,
 		isSupportSystemRole: isSupportSystemRole,
 	}
 
+	// key格式为 API Key|Secret Key 时通过OAuth获取access_token, 否则直接作为access_token使用
+	if split := gstr.Split(key, "|"); len(split) == 2 {
+		client.apiKey = split[0]
+		client.secretKey = split[1]
+	}
+
 	if baseURL != "" {
 		logger.Infof(ctx, "NewClient Baidu model: %s, baseURL: %s", model, baseURL)
 		client.baseURL = baseURL
@@ -48,18 +63,58 @@ func NewClient(ctx context.Context, model, key, baseURL, path string, isSupportS
 	return client
 }
 
+// NewQianfanClient 千帆ModelBuilder v2, 兼容OpenAI接口, 支持tools
+// key为IAM鉴权的API Key(bce-v3/...), 格式为 appid|API Key 时同时携带appid请求头
+func NewQianfanClient(ctx context.Context, model, key, baseURL, path string, isSupportSystemRole *bool, proxyURL ...string) *Client {
+
+	logger.Infof(ctx, "NewQianfanClient Baidu model: %s, key: %s", model, key)
+
+	client := &Client{
+		baseURL:             "https://qianfan.baidubce.com/v2",
+		path:                qianfanChatPath,
+		isSupportSystemRole: isSupportSystemRole,
+		isQianfan:           true,
+	}
+
+	if baseURL != "" {
+		logger.Infof(ctx, "NewQianfanClient Baidu model: %s, baseURL: %s", model, baseURL)
+		client.baseURL = baseURL
+	}
+
+	if path != "" {
+		logger.Infof(ctx, "NewQianfanClient Baidu model: %s, path: %s", model, path)
+		client.path = path
+	}
+
+	if len(proxyURL) > 0 && proxyURL[0] != "" {
+		logger.Infof(ctx, "NewQianfanClient Baidu model: %s, proxyURL: %s", model, proxyURL[0])
+		client.proxyURL = proxyURL[0]
+	}
+
+	client.header = make(map[string]string)
+
+	if split := gstr.Split(key, "|"); len(split) == 2 {
+		client.header["appid"] = split[0]
+		client.header["Authorization"] = "Bearer " + split[1]
+	} else {
+		client.header["Authorization"] = "Bearer " + key
+	}
+
+	return client
+}
+
 func (c *Client) requestErrorHandler(ctx context.Context, response *gclient.Response) (err error) {
 	return sdkerr.NewRequestError(500, errors.New(fmt.Sprintf("error, status code: %d, response: %s", response.StatusCode, response.ReadAllString())))
 }
 
-func (c *Client) apiErrorHandler(response *model.BaiduChatCompletionRes) error {
+func (c *Client) apiErrorHandler(errorCode int, response any) error {
 
-	switch response.ErrorCode {
+	switch errorCode {
 	case 336103, 336007:
 		return sdkerr.ERR_CONTEXT_LENGTH_EXCEEDED
 	case 4, 18, 336501:
 		return sdkerr.ERR_RATE_LIMIT_EXCEEDED
 	}
 
-	return sdkerr.NewApiError(500, response.ErrorCode, gjson.MustEncodeString(response), "api_error", "")
+	return sdkerr.NewApiError(500, errorCode, gjson.MustEncodeString(response), "api_error", "")
 }
@@ -12,13 +12,16 @@ import (
 	"github.com/iimeta/fastapi-sdk/consts"
 	"github.com/iimeta/fastapi-sdk/logger"
 	"github.com/iimeta/fastapi-sdk/model"
-	"github.com/iimeta/fastapi-sdk/util"
 	"github.com/iimeta/go-openai"
 	"io"
 )
 
 func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletionRequest) (res model.ChatCompletionResponse, err error) {
 
+	if c.isQianfan {
+		return c.qianfanChatCompletion(ctx, request)
+	}
+
 	logger.Infof(ctx, "ChatCompletion Baidu model: %s start", request.Model)
 
 	now := gtime.TimestampMilli()
@@ -58,8 +61,12 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 		chatCompletionReq.ResponseFormat = gconv.String(request.ResponseFormat.Type)
 	}
 
+	if request.EnableSearch {
+		chatCompletionReq.EnableCitation = true
+	}
+
 	chatCompletionRes := new(model.BaiduChatCompletionRes)
-	if _, err = util.HttpPost(ctx, fmt.Sprintf("%s?access_token=%s", c.baseURL+c.path, c.accessToken), nil, chatCompletionReq, &chatCompletionRes, c.proxyURL); err != nil {
+	if err = c.httpPost(ctx, c.baseURL+c.path, chatCompletionReq, &chatCompletionRes); err != nil {
 		logger.Errorf(ctx, "ChatCompletion Baidu model: %s, error: %v", request.Model, err)
 		return
 	}
@@ -67,7 +74,7 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 	if chatCompletionRes.ErrorCode != 0 {
 		logger.Errorf(ctx, "ChatCompletion Baidu model: %s, chatCompletionRes: %s", request.Model, gjson.MustEncodeString(chatCompletionRes))
 
-		err = c.apiErrorHandler(chatCompletionRes)
+		err = c.apiErrorHandler(chatCompletionRes.ErrorCode, chatCompletionRes)
 		logger.Errorf(ctx, "ChatCompletion Baidu model: %s, error: %v", request.Model, err)
 
 		return
@@ -87,11 +94,20 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 		Usage: chatCompletionRes.Usage,
 	}
 
+	if chatCompletionRes.SearchInfo != nil {
+		res.Citations = common.ConvSearchResults(chatCompletionRes.SearchInfo.SearchResults)
+		res.Choices[0].Message.Citations = res.Citations
+	}
+
 	return res, nil
 }
 
 func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCompletionRequest) (responseChan chan *model.ChatCompletionResponse, err error) {
 
+	if c.isQianfan {
+		return c.qianfanChatCompletionStream(ctx, request)
+	}
+
 	logger.Infof(ctx, "ChatCompletionStream Baidu model: %s start", request.Model)
 
 	now := gtime.TimestampMilli()
@@ -132,7 +148,11 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 		chatCompletionReq.ResponseFormat = gconv.String(request.ResponseFormat.Type)
 	}
 
-	stream, err := util.SSEClient(ctx, fmt.Sprintf("%s?access_token=%s", c.baseURL+c.path, c.accessToken), nil, chatCompletionReq, c.proxyURL, c.requestErrorHandler)
+	if request.EnableSearch {
+		chatCompletionReq.EnableCitation = true
+	}
+
+	stream, firstResponse, firstErr, err := c.sseClient(ctx, c.baseURL+c.path, chatCompletionReq)
 	if err != nil {
 		logger.Errorf(ctx, "ChatCompletionStream Baidu model: %s, error: %v", request.Model, err)
 		return responseChan, err
@@ -153,9 +173,26 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 			logger.Infof(ctx, "ChatCompletionStream Baidu model: %s connTime: %d ms, duration: %d ms, totalTime: %d ms", request.Model, duration-now, end-duration, end-now)
 		}()
 
+		var (
+			isFirst         = true
+			isCitationsSent = false
+		)
+
 		for {
 
-			streamResponse, err := stream.Recv()
+			var (
+				streamResponse []byte
+				err            error
+			)
+
+			// 首个响应已在建立连接时读取
+			if isFirst {
+				streamResponse, err = firstResponse, firstErr
+				isFirst = false
+			} else {
+				streamResponse, err = stream.Recv()
+			}
+
 			if err != nil && !errors.Is(err, io.EOF) {
 
 				if !errors.Is(err, context.Canceled) {
@@ -191,7 +228,7 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 			if chatCompletionRes.ErrorCode != 0 {
 				logger.Errorf(ctx, "ChatCompletionStream Baidu model: %s, chatCompletionRes: %s", request.Model, gjson.MustEncodeString(chatCompletionRes))
 
-				err = c.apiErrorHandler(chatCompletionRes)
+				err = c.apiErrorHandler(chatCompletionRes.ErrorCode, chatCompletionRes)
 				logger.Errorf(ctx, "ChatCompletionStream Baidu model: %s, error: %v", request.Model, err)
 
 				end := gtime.TimestampMilli()
@@ -221,6 +258,13 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 				ConnTime: duration - now,
 			}
 
+			// 搜索溯源信息只在首次返回时下发
+			if chatCompletionRes.SearchInfo != nil && len(chatCompletionRes.SearchInfo.SearchResults) > 0 && !isCitationsSent {
+				response.Citations = common.ConvSearchResults(chatCompletionRes.SearchInfo.SearchResults)
+				response.Choices[0].Delta.Citations = response.Citations
+				isCitationsSent = true
+			}
+
 			if errors.Is(err, io.EOF) || chatCompletionRes.IsEnd {
 				logger.Infof(ctx, "ChatCompletionStream Baidu model: %s finished", request.Model)
 
@@ -2,10 +2,166 @@ package baidu
 
 import (
 	"context"
+	"fmt"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/iimeta/fastapi-sdk/common"
+	"github.com/iimeta/fastapi-sdk/logger"
 	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
+	"github.com/iimeta/fastapi-sdk/util"
+	"github.com/iimeta/go-openai"
 )
 
+// 单次请求最多支持16条文本
+const embeddingBatchSize = 16
+
+// 向量模型对应的接口路径
+var embeddingPaths = map[string]string{
+	"embedding-v1": "/wenxinworkshop/embeddings/embedding-v1",
+	"bge-large-zh": "/wenxinworkshop/embeddings/bge_large_zh",
+	"bge-large-en": "/wenxinworkshop/embeddings/bge_large_en",
+	"tao-8k":       "/wenxinworkshop/embeddings/tao_8k",
+}
+
 func (c *Client) Embeddings(ctx context.Context, request model.EmbeddingRequest) (res model.EmbeddingResponse, err error) {
-	//TODO implement me
-	panic("implement me")
+
+	logger.Infof(ctx, "Embeddings Baidu model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "Embeddings Baidu model: %s totalTime: %d ms", request.Model, res.TotalTime)
+	}()
+
+	inputs, err := common.EmbeddingInputs(request.Input)
+	if err != nil {
+		logger.Errorf(ctx, "Embeddings Baidu model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	res = model.EmbeddingResponse{
+		Object: "list",
+		Model:  request.Model,
+		Usage:  new(model.Usage),
+	}
+
+	if c.isQianfan {
+
+		path := c.path
+		if path == qianfanChatPath {
+			path = "/embeddings"
+		}
+
+		// 与旧版接口一致, 按单次请求上限分批
+		for i := 0; i < len(inputs); i += embeddingBatchSize {
+
+			batchInputs := inputs[i:min(i+embeddingBatchSize, len(inputs))]
+
+			embeddingReq := model.EmbeddingRequest{
+				Input: batchInputs,
+				Model: request.Model,
+				User:  request.User,
+			}
+
+			embeddingRes := new(model.BaiduQianfanEmbeddingRes)
+			if _, err = util.HttpPost(ctx, c.baseURL+path, c.header, embeddingReq, &embeddingRes, c.proxyURL); err != nil {
+				logger.Errorf(ctx, "Embeddings Baidu model: %s, error: %v", request.Model, err)
+				return res, err
+			}
+
+			if embeddingRes.Error != nil && embeddingRes.Error.Code != "" {
+				logger.Errorf(ctx, "Embeddings Baidu model: %s, embeddingRes: %s", request.Model, gjson.MustEncodeString(embeddingRes))
+
+				err = c.qianfanApiErrorHandler(embeddingRes.Error)
+				logger.Errorf(ctx, "Embeddings Baidu model: %s, error: %v", request.Model, err)
+
+				return res, err
+			}
+
+			if err = checkEmbeddingCount(len(batchInputs), len(embeddingRes.Data)); err != nil {
+				logger.Errorf(ctx, "Embeddings Baidu model: %s, error: %v", request.Model, err)
+				return res, err
+			}
+
+			for _, data := range embeddingRes.Data {
+				data.Index += i
+				res.Data = append(res.Data, data)
+			}
+
+			if embeddingRes.Usage != nil {
+				res.Usage.PromptTokens += embeddingRes.Usage.PromptTokens
+				res.Usage.TotalTokens += embeddingRes.Usage.TotalTokens
+			}
+		}
+
+		logger.Infof(ctx, "Embeddings Baidu model: %s finished", request.Model)
+
+		return res, nil
+	}
+
+	path := c.path
+	if path == chatPath {
+		if path = embeddingPaths[gstr.ToLower(string(request.Model))]; path == "" {
+			path = "/wenxinworkshop/embeddings/" + gstr.ToLower(string(request.Model))
+		}
+	}
+
+	for i := 0; i < len(inputs); i += embeddingBatchSize {
+
+		batchInputs := inputs[i:min(i+embeddingBatchSize, len(inputs))]
+
+		embeddingReq := model.BaiduEmbeddingReq{
+			Input:  batchInputs,
+			UserId: request.User,
+		}
+
+		embeddingRes := new(model.BaiduEmbeddingRes)
+		if err = c.httpPost(ctx, c.baseURL+path, embeddingReq, &embeddingRes); err != nil {
+			logger.Errorf(ctx, "Embeddings Baidu model: %s, error: %v", request.Model, err)
+			return res, err
+		}
+
+		if embeddingRes.ErrorCode != 0 {
+			logger.Errorf(ctx, "Embeddings Baidu model: %s, embeddingRes: %s", request.Model, gjson.MustEncodeString(embeddingRes))
+
+			err = c.apiErrorHandler(embeddingRes.ErrorCode, embeddingRes)
+			logger.Errorf(ctx, "Embeddings Baidu model: %s, error: %v", request.Model, err)
+
+			return res, err
+		}
+
+		if err = checkEmbeddingCount(len(batchInputs), len(embeddingRes.Data)); err != nil {
+			logger.Errorf(ctx, "Embeddings Baidu model: %s, error: %v", request.Model, err)
+			return res, err
+		}
+
+		for _, data := range embeddingRes.Data {
+			res.Data = append(res.Data, openai.Embedding{
+				Object:    "embedding",
+				Embedding: data.Embedding,
+				Index:     i + data.Index,
+			})
+		}
+
+		if embeddingRes.Usage != nil {
+			res.Usage.PromptTokens += embeddingRes.Usage.PromptTokens
+			res.Usage.TotalTokens += embeddingRes.Usage.TotalTokens
+		}
+	}
+
+	logger.Infof(ctx, "Embeddings Baidu model: %s finished", request.Model)
+
+	return res, nil
+}
+
+// 返回的向量数量与输入不一致时无法对应index
+func checkEmbeddingCount(inputs, embeddings int) error {
+
+	if inputs != embeddings {
+		return sdkerr.NewApiError(500, "embedding_count_mismatch", fmt.Sprintf("Expected %d embeddings, got %d.", inputs, embeddings), "api_error", "")
+	}
+
+	return nil
 }
@@ -2,10 +2,110 @@ package baidu
 
 import (
 	"context"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/iimeta/fastapi-sdk/logger"
 	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
+	"github.com/iimeta/fastapi-sdk/util"
 )
 
+// 绘图模型对应的接口路径
+var imagePaths = map[string]string{
+	"stable-diffusion-xl": "/wenxinworkshop/text2image/sd_xl",
+}
+
 func (c *Client) Image(ctx context.Context, request model.ImageRequest) (res model.ImageResponse, err error) {
-	//TODO implement me
-	panic("implement me")
+
+	logger.Infof(ctx, "Image Baidu model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "Image Baidu model: %s totalTime: %d ms", request.Model, res.TotalTime)
+	}()
+
+	if c.isQianfan {
+
+		path := c.path
+		if path == qianfanChatPath {
+			path = "/images/generations"
+		}
+
+		imageRes := new(model.BaiduQianfanImageRes)
+		if _, err = util.HttpPost(ctx, c.baseURL+path, c.header, request, &imageRes, c.proxyURL); err != nil {
+			logger.Errorf(ctx, "Image Baidu model: %s, error: %v", request.Model, err)
+			return res, err
+		}
+
+		if imageRes.Error != nil && imageRes.Error.Code != "" {
+			logger.Errorf(ctx, "Image Baidu model: %s, imageRes: %s", request.Model, gjson.MustEncodeString(imageRes))
+
+			err = c.qianfanApiErrorHandler(imageRes.Error)
+			logger.Errorf(ctx, "Image Baidu model: %s, error: %v", request.Model, err)
+
+			return res, err
+		}
+
+		res = model.ImageResponse{
+			Created: imageRes.Created,
+			Data:    imageRes.Data,
+		}
+
+		logger.Infof(ctx, "Image Baidu model: %s finished", request.Model)
+
+		return res, nil
+	}
+
+	path := c.path
+	if path == chatPath {
+		if path = imagePaths[gstr.ToLower(request.Model)]; path == "" {
+			path = "/wenxinworkshop/text2image/" + gstr.ToLower(request.Model)
+		}
+	}
+
+	imageReq := model.BaiduImageReq{
+		Prompt: request.Prompt,
+		Size:   gstr.ReplaceByMap(request.Size, map[string]string{"*": "x", "×": "x"}),
+		N:      request.N,
+		UserId: request.User,
+	}
+
+	imageRes := new(model.BaiduImageRes)
+	if err = c.httpPost(ctx, c.baseURL+path, imageReq, &imageRes); err != nil {
+		logger.Errorf(ctx, "Image Baidu model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	if imageRes.ErrorCode != 0 {
+		logger.Errorf(ctx, "Image Baidu model: %s, imageRes: %s", request.Model, gjson.MustEncodeString(imageRes))
+
+		err = c.apiErrorHandler(imageRes.ErrorCode, imageRes)
+		logger.Errorf(ctx, "Image Baidu model: %s, error: %v", request.Model, err)
+
+		return res, err
+	}
+
+	res = model.ImageResponse{
+		Created: imageRes.Created,
+	}
+
+	for _, data := range imageRes.Data {
+		res.Data = append(res.Data, model.ImageResponseDataInner{
+			B64JSON: data.B64Image,
+		})
+	}
+
+	logger.Infof(ctx, "Image Baidu model: %s finished", request.Model)
+
+	return res, nil
+}
+
+func (c *Client) ImageEdit(ctx context.Context, request model.ImageEditRequest) (res model.ImageResponse, err error) {
+	return res, sdkerr.NewApiError(400, "unsupported_operation", "Baidu does not support image edit.", "invalid_request_error", "")
+}
+
+func (c *Client) ImageVariation(ctx context.Context, request model.ImageVariationRequest) (res model.ImageResponse, err error) {
+	return res, sdkerr.NewApiError(400, "unsupported_operation", "Baidu does not support image variation.", "invalid_request_error", "")
 }
@@ -0,0 +1,322 @@
+package baidu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/os/grpool"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/iimeta/fastapi-sdk/common"
+	"github.com/iimeta/fastapi-sdk/consts"
+	"github.com/iimeta/fastapi-sdk/logger"
+	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
+	"github.com/iimeta/fastapi-sdk/util"
+	"io"
+)
+
+func (c *Client) qianfanChatCompletion(ctx context.Context, request model.ChatCompletionRequest) (res model.ChatCompletionResponse, err error) {
+
+	logger.Infof(ctx, "ChatCompletion Qianfan model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "ChatCompletion Qianfan model: %s totalTime: %d ms", request.Model, res.TotalTime)
+	}()
+
+	chatCompletionRes := new(model.BaiduQianfanChatCompletionRes)
+	if _, err = util.HttpPost(ctx, c.baseURL+c.path, c.header, c.convQianfanRequest(request), &chatCompletionRes, c.proxyURL); err != nil {
+		logger.Errorf(ctx, "ChatCompletion Qianfan model: %s, error: %v", request.Model, err)
+		return
+	}
+
+	if chatCompletionRes.Error != nil && chatCompletionRes.Error.Code != "" {
+		logger.Errorf(ctx, "ChatCompletion Qianfan model: %s, chatCompletionRes: %s", request.Model, gjson.MustEncodeString(chatCompletionRes))
+
+		err = c.qianfanApiErrorHandler(chatCompletionRes.Error)
+		logger.Errorf(ctx, "ChatCompletion Qianfan model: %s, error: %v", request.Model, err)
+
+		return
+	}
+
+	res = model.ChatCompletionResponse{
+		ID:      consts.COMPLETION_ID_PREFIX + chatCompletionRes.Id,
+		Object:  consts.COMPLETION_OBJECT,
+		Created: chatCompletionRes.Created,
+		Model:   request.Model,
+		Usage:   chatCompletionRes.Usage,
+	}
+
+	res.Citations = common.ConvSearchResults(chatCompletionRes.SearchResults)
+
+	for _, choice := range chatCompletionRes.Choices {
+
+		if choice.Message == nil {
+			continue
+		}
+
+		res.Choices = append(res.Choices, model.ChatCompletionChoice{
+			Index: choice.Index,
+			Message: &model.ChatCompletionMessage{
+				Role:         choice.Message.Role,
+				Content:      choice.Message.Content,
+				Refusal:      choice.Message.Refusal,
+				MultiContent: choice.Message.MultiContent,
+				Name:         choice.Message.Name,
+				FunctionCall: choice.Message.FunctionCall,
+				ToolCalls:    choice.Message.ToolCalls,
+				ToolCallID:   choice.Message.ToolCallID,
+				Audio:        choice.Message.Audio,
+				Citations:    res.Citations,
+			},
+			FinishReason: choice.FinishReason,
+		})
+	}
+
+	return res, nil
+}
+
+func (c *Client) qianfanChatCompletionStream(ctx context.Context, request model.ChatCompletionRequest) (responseChan chan *model.ChatCompletionResponse, err error) {
+
+	logger.Infof(ctx, "ChatCompletionStream Qianfan model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		if err != nil {
+			logger.Infof(ctx, "ChatCompletionStream Qianfan model: %s totalTime: %d ms", request.Model, gtime.TimestampMilli()-now)
+		}
+	}()
+
+	stream, err := util.SSEClient(ctx, c.baseURL+c.path, c.header, c.convQianfanRequest(request), c.proxyURL, c.qianfanRequestErrorHandler)
+	if err != nil {
+		logger.Errorf(ctx, "ChatCompletionStream Qianfan model: %s, error: %v", request.Model, err)
+		return responseChan, err
+	}
+
+	duration := gtime.TimestampMilli()
+
+	responseChan = make(chan *model.ChatCompletionResponse)
+
+	if err = grpool.AddWithRecover(ctx, func(ctx context.Context) {
+
+		defer func() {
+			if err := stream.Close(); err != nil {
+				logger.Errorf(ctx, "ChatCompletionStream Qianfan model: %s, stream.Close error: %v", request.Model, err)
+			}
+
+			end := gtime.TimestampMilli()
+			logger.Infof(ctx, "ChatCompletionStream Qianfan model: %s connTime: %d ms, duration: %d ms, totalTime: %d ms", request.Model, duration-now, end-duration, end-now)
+		}()
+
+		isCitationsSent := false
+
+		for {
+
+			streamResponse, err := stream.Recv()
+			if err != nil && !errors.Is(err, io.EOF) {
+
+				if !errors.Is(err, context.Canceled) {
+					logger.Errorf(ctx, "ChatCompletionStream Qianfan model: %s, error: %v", request.Model, err)
+				}
+
+				end := gtime.TimestampMilli()
+				responseChan <- &model.ChatCompletionResponse{
+					ConnTime:  duration - now,
+					Duration:  end - duration,
+					TotalTime: end - now,
+					Error:     err,
+				}
+
+				return
+			}
+
+			// 收到[DONE]或连接正常结束
+			if errors.Is(err, io.EOF) && len(gstr.Trim(string(streamResponse))) == 0 {
+				logger.Infof(ctx, "ChatCompletionStream Qianfan model: %s finished", request.Model)
+
+				end := gtime.TimestampMilli()
+				responseChan <- &model.ChatCompletionResponse{
+					ConnTime:  duration - now,
+					Duration:  end - duration,
+					TotalTime: end - now,
+					Error:     io.EOF,
+				}
+
+				return
+			}
+
+			chatCompletionRes := new(model.BaiduQianfanChatCompletionRes)
+			if err := gjson.Unmarshal(streamResponse, &chatCompletionRes); err != nil {
+				logger.Errorf(ctx, "ChatCompletionStream Qianfan model: %s, streamResponse: %s, error: %v", request.Model, streamResponse, err)
+
+				end := gtime.TimestampMilli()
+				responseChan <- &model.ChatCompletionResponse{
+					ConnTime:  duration - now,
+					Duration:  end - duration,
+					TotalTime: end - now,
+					Error:     errors.New(fmt.Sprintf("streamResponse: %s, error: %v", streamResponse, err)),
+				}
+
+				return
+			}
+
+			if chatCompletionRes.Error != nil && chatCompletionRes.Error.Code != "" {
+				logger.Errorf(ctx, "ChatCompletionStream Qianfan model: %s, chatCompletionRes: %s", request.Model, gjson.MustEncodeString(chatCompletionRes))
+
+				err = c.qianfanApiErrorHandler(chatCompletionRes.Error)
+				logger.Errorf(ctx, "ChatCompletionStream Qianfan model: %s, error: %v", request.Model, err)
+
+				end := gtime.TimestampMilli()
+				responseChan <- &model.ChatCompletionResponse{
+					ConnTime:  duration - now,
+					Duration:  end - duration,
+					TotalTime: end - now,
+					Error:     err,
+				}
+
+				return
+			}
+
+			response := &model.ChatCompletionResponse{
+				ID:       consts.COMPLETION_ID_PREFIX + chatCompletionRes.Id,
+				Object:   consts.COMPLETION_STREAM_OBJECT,
+				Created:  chatCompletionRes.Created,
+				Model:    request.Model,
+				Usage:    chatCompletionRes.Usage,
+				ConnTime: duration - now,
+			}
+
+			// 搜索溯源信息只在首次返回时下发
+			if len(chatCompletionRes.SearchResults) > 0 && !isCitationsSent {
+				response.Citations = common.ConvSearchResults(chatCompletionRes.SearchResults)
+				isCitationsSent = true
+			}
+
+			for _, choice := range chatCompletionRes.Choices {
+
+				if choice.Delta == nil {
+					continue
+				}
+
+				response.Choices = append(response.Choices, model.ChatCompletionChoice{
+					Index: choice.Index,
+					Delta: &model.ChatCompletionStreamChoiceDelta{
+						Content:      choice.Delta.Content,
+						Role:         choice.Delta.Role,
+						FunctionCall: choice.Delta.FunctionCall,
+						ToolCalls:    choice.Delta.ToolCalls,
+						Refusal:      choice.Delta.Refusal,
+						Audio:        choice.Delta.Audio,
+						Citations:    response.Citations,
+					},
+					FinishReason: choice.FinishReason,
+				})
+			}
+
+			end := gtime.TimestampMilli()
+			response.Duration = end - duration
+			response.TotalTime = end - now
+
+			responseChan <- response
+		}
+	}, nil); err != nil {
+		logger.Errorf(ctx, "ChatCompletionStream Qianfan model: %s, error: %v", request.Model, err)
+		return responseChan, err
+	}
+
+	return responseChan, nil
+}
+
+// convQianfanRequest 千帆v2兼容OpenAI格式, 模型通过model字段指定
+func (c *Client) convQianfanRequest(request model.ChatCompletionRequest) model.BaiduQianfanChatCompletionReq {
+
+	messages := make([]model.ChatCompletionMessage, len(request.Messages))
+	copy(messages, request.Messages)
+
+	// 兼容OpenAI格式, 消息原样透传, 仅去掉SDK扩展的思考块与搜索溯源, 不支持system角色时将其转换为user
+	for i := range messages {
+
+		messages[i].ThinkingBlocks = nil
+		messages[i].Citations = nil
+
+		if c.isSupportSystemRole != nil && !*c.isSupportSystemRole && messages[i].Role == consts.ROLE_SYSTEM {
+			messages[i].Role = consts.ROLE_USER
+		}
+	}
+
+	chatCompletionReq := model.BaiduQianfanChatCompletionReq{
+		Model:               request.Model,
+		Messages:            messages,
+		Stream:              request.Stream,
+		Temperature:         request.Temperature,
+		TopP:                request.TopP,
+		MaxCompletionTokens: request.MaxCompletionTokens,
+		Seed:                request.Seed,
+		Stop:                request.Stop,
+		FrequencyPenalty:    request.FrequencyPenalty,
+		PresencePenalty:     request.PresencePenalty,
+		Tools:               request.Tools,
+		ToolChoice:          request.ToolChoice,
+		ParallelToolCalls:   request.ParallelToolCalls,
+		User:                request.User,
+	}
+
+	if chatCompletionReq.MaxCompletionTokens == 0 {
+		chatCompletionReq.MaxCompletionTokens = request.MaxTokens
+	}
+
+	if request.Stream && request.StreamOptions != nil {
+		chatCompletionReq.StreamOptions = request.StreamOptions
+	}
+
+	if request.ResponseFormat != nil {
+		chatCompletionReq.ResponseFormat = request.ResponseFormat
+	}
+
+	if request.EnableSearch {
+		chatCompletionReq.WebSearch = &model.BaiduQianfanWebSearch{
+			Enable:         true,
+			EnableCitation: true,
+			EnableTrace:    true,
+		}
+	}
+
+	return chatCompletionReq
+}
+
+func (c *Client) qianfanRequestErrorHandler(ctx context.Context, response *gclient.Response) error {
+
+	errRes := model.BaiduQianfanErrorResponse{}
+	if err := json.NewDecoder(response.Body).Decode(&errRes); err != nil || errRes.Error == nil {
+
+		reqErr := &sdkerr.RequestError{
+			HttpStatusCode: response.StatusCode,
+			Err:            err,
+		}
+
+		if errRes.Error != nil {
+			reqErr.Err = errors.New(gjson.MustEncodeString(errRes.Error))
+		}
+
+		return reqErr
+	}
+
+	return c.qianfanApiErrorHandler(errRes.Error)
+}
+
+func (c *Client) qianfanApiErrorHandler(apiError *model.BaiduQianfanError) error {
+
+	switch {
+	case gstr.Contains(apiError.Code, "rate_limit"):
+		return sdkerr.ERR_RATE_LIMIT_EXCEEDED
+	case gstr.Contains(apiError.Code, "context_length"), gstr.Contains(apiError.Message, "max length"):
+		return sdkerr.ERR_CONTEXT_LENGTH_EXCEEDED
+	}
+
+	return sdkerr.NewApiError(500, apiError.Code, gjson.MustEncodeString(apiError), "api_error", "")
+}
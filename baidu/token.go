@@ -0,0 +1,162 @@
+package baidu
+
+import (
+	"context"
+	"fmt"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/iimeta/fastapi-sdk/logger"
+	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
+	"github.com/iimeta/fastapi-sdk/util"
+	"net/url"
+	"sync"
+)
+
+// tokenURL 获取access_token的地址, 测试时替换为本地服务
+var tokenURL = "https://aip.baidubce.com/oauth/2.0/token"
+
+// accessTokens 进程内access_token缓存, key为API Key|Secret Key, 同一API Key轮换Secret Key后不会复用旧凭证的access_token
+var accessTokens sync.Map
+
+type accessToken struct {
+	// 同一凭证同时只有一个请求去获取access_token, 其余请求等待并复用结果
+	mutex     sync.Mutex
+	token     string
+	refreshAt int64
+}
+
+// getAccessToken 获取access_token, 未配置Secret Key时直接使用key作为access_token
+// expired不为空且与缓存中的access_token相同时强制刷新, 已被其它请求刷新过则直接返回新的access_token
+func (c *Client) getAccessToken(ctx context.Context, expired string) (string, error) {
+
+	if c.secretKey == "" {
+		return c.accessToken, nil
+	}
+
+	value, _ := accessTokens.LoadOrStore(c.apiKey+"|"+c.secretKey, new(accessToken))
+	token := value.(*accessToken)
+
+	token.mutex.Lock()
+	defer token.mutex.Unlock()
+
+	if token.token != "" && token.token != expired && gtime.Timestamp() < token.refreshAt {
+		return token.token, nil
+	}
+
+	logger.Infof(ctx, "getAccessToken Baidu apiKey: %s start", c.apiKey)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		logger.Infof(ctx, "getAccessToken Baidu apiKey: %s totalTime: %d ms", c.apiKey, gtime.TimestampMilli()-now)
+	}()
+
+	accessTokenRes := new(model.BaiduAccessTokenRes)
+	if _, err := util.HttpPost(ctx, fmt.Sprintf("%s?grant_type=client_credentials&client_id=%s&client_secret=%s", tokenURL, url.QueryEscape(c.apiKey), url.QueryEscape(c.secretKey)), nil, nil, &accessTokenRes, c.proxyURL); err != nil {
+		logger.Errorf(ctx, "getAccessToken Baidu apiKey: %s, error: %v", c.apiKey, err)
+		return "", err
+	}
+
+	if accessTokenRes.Error != "" || accessTokenRes.AccessToken == "" {
+		logger.Errorf(ctx, "getAccessToken Baidu apiKey: %s, accessTokenRes: %s", c.apiKey, gjson.MustEncodeString(accessTokenRes))
+		return "", sdkerr.NewApiError(401, accessTokenRes.Error, gjson.MustEncodeString(accessTokenRes), "invalid_request_error", "")
+	}
+
+	// 在有效期剩余1/10时提前刷新
+	token.token = accessTokenRes.AccessToken
+	token.refreshAt = gtime.Timestamp() + accessTokenRes.ExpiresIn*9/10
+
+	logger.Infof(ctx, "getAccessToken Baidu apiKey: %s, expiresIn: %d finished", c.apiKey, accessTokenRes.ExpiresIn)
+
+	return token.token, nil
+}
+
+// isAccessTokenError 110: access_token无效, 111: access_token过期
+func isAccessTokenError(errorCode int) bool {
+	return errorCode == 110 || errorCode == 111
+}
+
+// withAccessToken 携带access_token执行请求, fn返回的错误码为access_token失效时刷新后重试一次,
+// 非流式请求与流式连接共用, 保证两者的刷新逻辑一致
+func (c *Client) withAccessToken(ctx context.Context, fn func(accessToken string) (errorCode int, err error)) error {
+
+	accessToken, err := c.getAccessToken(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	errorCode, err := fn(accessToken)
+	if err != nil || c.secretKey == "" || !isAccessTokenError(errorCode) {
+		return err
+	}
+
+	logger.Infof(ctx, "withAccessToken Baidu apiKey: %s, errorCode: %d, refresh access_token and retry", c.apiKey, errorCode)
+
+	if accessToken, err = c.getAccessToken(ctx, accessToken); err != nil {
+		return err
+	}
+
+	_, err = fn(accessToken)
+
+	return err
+}
+
+// httpPost 携带access_token发送请求, access_token失效时刷新后重试一次
+func (c *Client) httpPost(ctx context.Context, url string, data, result any) error {
+
+	var bytes []byte
+
+	if err := c.withAccessToken(ctx, func(accessToken string) (errorCode int, err error) {
+
+		errorRes := new(model.BaiduErrorRes)
+		if bytes, err = util.HttpPost(ctx, fmt.Sprintf("%s?access_token=%s", url, accessToken), nil, data, &errorRes, c.proxyURL); err != nil {
+			return 0, err
+		}
+
+		return errorRes.ErrorCode, nil
+
+	}); err != nil {
+		return err
+	}
+
+	return gjson.Unmarshal(bytes, result)
+}
+
+// sseClient 携带access_token建立流式连接并读取首个响应, access_token失效时以非SSE格式返回错误, 刷新后重新连接一次
+// 首个响应及其读取错误由调用方按正常流程处理
+func (c *Client) sseClient(ctx context.Context, url string, data any) (stream *util.StreamReader, firstResponse []byte, firstErr error, err error) {
+
+	err = c.withAccessToken(ctx, func(accessToken string) (errorCode int, err error) {
+
+		if stream != nil {
+			if err := stream.Close(); err != nil {
+				logger.Errorf(ctx, "sseClient Baidu url: %s, stream.Close error: %v", url, err)
+			}
+		}
+
+		if stream, err = util.SSEClient(ctx, fmt.Sprintf("%s?access_token=%s", url, accessToken), nil, data, c.proxyURL, c.requestErrorHandler); err != nil {
+			stream = nil
+			return 0, err
+		}
+
+		if firstResponse, firstErr = stream.Recv(); firstErr != nil && len(firstResponse) == 0 {
+			return 0, nil
+		}
+
+		errorRes := new(model.BaiduErrorRes)
+		if err := gjson.Unmarshal(firstResponse, &errorRes); err != nil {
+			return 0, nil
+		}
+
+		return errorRes.ErrorCode, nil
+	})
+
+	if err != nil && stream != nil {
+		if err := stream.Close(); err != nil {
+			logger.Errorf(ctx, "sseClient Baidu url: %s, stream.Close error: %v", url, err)
+		}
+		stream = nil
+	}
+
+	return stream, firstResponse, firstErr, err
+}
@@ -0,0 +1,163 @@
+package baidu
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/iimeta/fastapi-sdk/consts"
+	"github.com/iimeta/fastapi-sdk/model"
+)
+
+// newTokenServer 第一次签发的access_token调用接口时返回errorCode, 刷新后的access_token正常返回
+func newTokenServer(t *testing.T, errorCode int) (server *httptest.Server, tokens, calls *atomic.Int32) {
+
+	tokens = new(atomic.Int32)
+	calls = new(atomic.Int32)
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if r.URL.Path == "/oauth/2.0/token" {
+			_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":2592000}`, tokens.Add(1))
+			return
+		}
+
+		calls.Add(1)
+
+		accessToken := r.URL.Query().Get("access_token")
+		if accessToken == "token-1" || accessToken == "static" {
+			_, _ = fmt.Fprintf(w, `{"error_code":%d,"error_msg":"Access token expired"}`, errorCode)
+			return
+		}
+
+		if r.Header.Get("Accept") == "text/event-stream" {
+			_, _ = io.WriteString(w, "data: {\"id\":\"as-1\",\"result\":\"hello\",\"is_end\":true}\n\n")
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"id":"as-1","result":"hello"}`)
+	}))
+
+	tokenURL = server.URL + "/oauth/2.0/token"
+
+	t.Cleanup(func() {
+		server.Close()
+		tokenURL = "https://aip.baidubce.com/oauth/2.0/token"
+	})
+
+	return server, tokens, calls
+}
+
+func TestAccessTokenRetry(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		key        string
+		errorCode  int
+		stream     bool
+		wantErr    bool
+		wantTokens int32
+		wantCalls  int32
+	}{
+		{name: "chat refresh on 110", key: "chat-110|secret", errorCode: 110, wantTokens: 2, wantCalls: 2},
+		{name: "chat refresh on 111", key: "chat-111|secret", errorCode: 111, wantTokens: 2, wantCalls: 2},
+		{name: "stream refresh on 111", key: "stream-111|secret", errorCode: 111, stream: true, wantTokens: 2, wantCalls: 2},
+		{name: "other error is not retried", key: "chat-336003|secret", errorCode: 336003, wantErr: true, wantTokens: 1, wantCalls: 1},
+		{name: "static access_token is not refreshed", key: "static", errorCode: 111, wantErr: true, wantTokens: 0, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			server, tokens, calls := newTokenServer(t, tt.errorCode)
+
+			ctx := context.Background()
+			client := NewClient(ctx, "ernie-4.0-8k", tt.key, server.URL, "/chat", nil)
+
+			request := model.ChatCompletionRequest{
+				Model:    "ernie-4.0-8k",
+				Messages: []model.ChatCompletionMessage{{Role: consts.ROLE_USER, Content: "hi"}},
+				Stream:   tt.stream,
+			}
+
+			var (
+				content string
+				err     error
+			)
+
+			if tt.stream {
+
+				responseChan, streamErr := client.ChatCompletionStream(ctx, request)
+				if err = streamErr; err == nil {
+					for response := range responseChan {
+						if response.Error != nil {
+							if response.Error != io.EOF {
+								err = response.Error
+							}
+							break
+						}
+						content += fmt.Sprint(response.Choices[0].Delta.Content)
+					}
+				}
+
+			} else {
+
+				res, chatErr := client.ChatCompletion(ctx, request)
+				if err = chatErr; err == nil {
+					content = fmt.Sprint(res.Choices[0].Message.Content)
+				}
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && content != "hello" {
+				t.Errorf("content = %q, want hello", content)
+			}
+
+			if got := tokens.Load(); got != tt.wantTokens {
+				t.Errorf("token requests = %d, want %d", got, tt.wantTokens)
+			}
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("api requests = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestAccessTokenCacheKey(t *testing.T) {
+
+	server, tokens, _ := newTokenServer(t, 111)
+
+	ctx := context.Background()
+
+	first, err := NewClient(ctx, "ernie-4.0-8k", "cache-key|secret-1", server.URL, "/chat", nil).getAccessToken(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 同一API Key更换Secret Key后需重新获取access_token
+	second, err := NewClient(ctx, "ernie-4.0-8k", "cache-key|secret-2", server.URL, "/chat", nil).getAccessToken(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Fatalf("access_token = %s for both secret keys, want different tokens", first)
+	}
+
+	// 相同凭证复用缓存
+	if _, err = NewClient(ctx, "ernie-4.0-8k", "cache-key|secret-1", server.URL, "/chat", nil).getAccessToken(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tokens.Load(); got != 2 {
+		t.Errorf("token requests = %d, want 2", got)
+	}
+}
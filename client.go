@@ -20,8 +20,11 @@ type Client interface {
 	ChatCompletion(ctx context.Context, request model.ChatCompletionRequest) (res model.ChatCompletionResponse, err error)
 	ChatCompletionStream(ctx context.Context, request model.ChatCompletionRequest) (responseChan chan *model.ChatCompletionResponse, err error)
 	Image(ctx context.Context, request model.ImageRequest) (res model.ImageResponse, err error)
+	ImageEdit(ctx context.Context, request model.ImageEditRequest) (res model.ImageResponse, err error)
+	ImageVariation(ctx context.Context, request model.ImageVariationRequest) (res model.ImageResponse, err error)
 	Speech(ctx context.Context, request model.SpeechRequest) (res model.SpeechResponse, err error)
 	Transcription(ctx context.Context, request model.AudioRequest) (res model.AudioResponse, err error)
+	Translation(ctx context.Context, request model.AudioRequest) (res model.AudioResponse, err error)
 	Embeddings(ctx context.Context, request model.EmbeddingRequest) (res model.EmbeddingResponse, err error)
 	Moderations(ctx context.Context, request model.ModerationRequest) (res model.ModerationResponse, err error)
 }
@@ -37,6 +40,8 @@ func NewClient(ctx context.Context, corp, model, key, baseURL, path string, isSu
 		return openai.NewAzureClient(ctx, model, key, baseURL, path, isSupportSystemRole, proxyURL...)
 	case consts.CORP_BAIDU:
 		return baidu.NewClient(ctx, model, key, baseURL, path, isSupportSystemRole, proxyURL...)
+	case consts.CORP_BAIDU_QIANFAN:
+		return baidu.NewQianfanClient(ctx, model, key, baseURL, path, isSupportSystemRole, proxyURL...)
 	case consts.CORP_XFYUN:
 		return xfyun.NewClient(ctx, model, key, baseURL, path, isSupportSystemRole, proxyURL...)
 	case consts.CORP_ALIYUN:
@@ -51,6 +56,8 @@ func NewClient(ctx context.Context, corp, model, key, baseURL, path string, isSu
 		return deepseek.NewClientBaidu(ctx, model, key, baseURL, path, isSupportSystemRole, proxyURL...)
 	case consts.CORP_360AI:
 		return ai360.NewClient(ctx, model, key, baseURL, path, isSupportSystemRole, proxyURL...)
+	case consts.CORP_MIDJOURNEY:
+		return NewMidjourneyImageClient(ctx, model, key, baseURL, path, isSupportSystemRole, proxyURL...)
 	case consts.CORP_ANTHROPIC:
 		return anthropic.NewClient(ctx, model, key, baseURL, path, isSupportSystemRole, proxyURL...)
 	case consts.CORP_GCP_CLAUDE:
@@ -1,10 +1,20 @@
 package common
 
 import (
+	"bytes"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/gogf/gf/v2/text/gstr"
 	"github.com/iimeta/fastapi-sdk/consts"
 	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
 )
 
 func HandleMessages(messages []model.ChatCompletionMessage, isSupportSystemRole bool) []model.ChatCompletionMessage {
@@ -16,6 +26,9 @@ func HandleMessages(messages []model.ChatCompletionMessage, isSupportSystemRole
 
 	for _, message := range messages {
 		if message.Content != "" {
+			// 回传的历史消息中的思考块与搜索溯源为SDK扩展字段, 厂商接口不支持
+			message.ThinkingBlocks = nil
+			message.Citations = nil
 			newMessages = append(newMessages, message)
 		}
 	}
@@ -79,3 +92,154 @@ func GetMime(url string) (mimeType string, data string) {
 
 	return mimeType, data
 }
+
+// ReadImageFile 读取上传图片的文件名与内容
+func ReadImageFile(file *model.ImageFile) (fileName string, data []byte, err error) {
+
+	if file == nil {
+		return "", nil, errors.New("image file is required")
+	}
+
+	if file.Reader != nil {
+		if data, err = io.ReadAll(file.Reader); err != nil {
+			return "", nil, err
+		}
+	} else if file.Base64 != "" {
+		_, b64 := GetMime(file.Base64)
+		if data, err = base64.StdEncoding.DecodeString(b64); err != nil {
+			return "", nil, err
+		}
+	} else {
+		return "", nil, errors.New("image file has no content")
+	}
+
+	if fileName = file.FileName; fileName == "" {
+
+		fileName = "image.png"
+
+		mimeType := http.DetectContentType(data)
+		for ext, value := range consts.MIME_TYPE_MAP {
+			if value == mimeType && gstr.HasPrefix(mimeType, "image/") {
+				fileName = "image." + ext
+				break
+			}
+		}
+	}
+
+	return fileName, data, nil
+}
+
+// ImageFileURL 获取上传图片的URL, 无URL时转换为data URI
+func ImageFileURL(file *model.ImageFile) (string, error) {
+
+	if file == nil {
+		return "", errors.New("image file is required")
+	}
+
+	if file.URL != "" {
+		return file.URL, nil
+	}
+
+	if file.Base64 != "" && gstr.HasPrefix(file.Base64, "data:") {
+		return file.Base64, nil
+	}
+
+	_, data, err := ReadImageFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("data:%s;base64,%s", http.DetectContentType(data), base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// ConvAlphaMask 将OpenAI格式的遮罩(完全透明区域为编辑区域)转换为黑白遮罩(白色为编辑区域, 黑色为保留区域)并返回data URI,
+// 遮罩为URL时无法读取透明通道, 视为厂商原生的黑白遮罩直接返回
+func ConvAlphaMask(file *model.ImageFile) (string, error) {
+
+	if file != nil && file.URL != "" {
+		return file.URL, nil
+	}
+
+	_, data, err := ReadImageFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	bounds := img.Bounds()
+	mask := image.NewGray(bounds)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a == 0 {
+				mask.SetGray(x, y, color.Gray{Y: 255})
+			} else {
+				mask.SetGray(x, y, color.Gray{Y: 0})
+			}
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err = png.Encode(buf, mask); err != nil {
+		return "", err
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// EmbeddingInputs 将向量请求的input转换为文本列表, 不支持token数组形式的input
+func EmbeddingInputs(input any) ([]string, error) {
+
+	var inputs []string
+
+	switch value := input.(type) {
+	case string:
+		inputs = []string{value}
+	case []string:
+		inputs = value
+	case []interface{}:
+
+		inputs = make([]string, 0, len(value))
+
+		for _, item := range value {
+
+			text, ok := item.(string)
+			if !ok {
+				return nil, sdkerr.NewApiError(400, "invalid_input", "Only string or string array input is supported.", "invalid_request_error", "input")
+			}
+
+			inputs = append(inputs, text)
+		}
+	default:
+		return nil, sdkerr.NewApiError(400, "invalid_input", "Only string or string array input is supported.", "invalid_request_error", "input")
+	}
+
+	if len(inputs) == 0 {
+		return nil, sdkerr.NewApiError(400, "invalid_input", "Input is required.", "invalid_request_error", "input")
+	}
+
+	return inputs, nil
+}
+
+// ConvSearchResults 将百度、阿里云的搜索溯源信息转换为Citations
+func ConvSearchResults(searchResults []model.SearchResult) []model.Citation {
+
+	if len(searchResults) == 0 {
+		return nil
+	}
+
+	citations := make([]model.Citation, 0, len(searchResults))
+	for _, result := range searchResults {
+		citations = append(citations, model.Citation{
+			Index: result.Index,
+			URL:   result.Url,
+			Title: result.Title,
+		})
+	}
+
+	return citations
+}
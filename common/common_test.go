@@ -0,0 +1,66 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/iimeta/fastapi-sdk/consts"
+	"github.com/iimeta/fastapi-sdk/model"
+)
+
+func TestHandleMessagesStripsExtensions(t *testing.T) {
+
+	messages := []model.ChatCompletionMessage{
+		{Role: consts.ROLE_USER, Content: "What's new today?"},
+		{
+			Role:           consts.ROLE_ASSISTANT,
+			Content:        "Here is the news.",
+			ThinkingBlocks: []model.ThinkingBlock{{Type: consts.CONTENT_TYPE_THINKING, Thinking: "search", Signature: "sig"}},
+			Citations:      []model.Citation{{Index: 1, URL: "https://example.com", Title: "Example"}},
+		},
+		{Role: consts.ROLE_USER, Content: "Tell me more."},
+	}
+
+	for _, message := range HandleMessages(messages, true) {
+		if message.ThinkingBlocks != nil || message.Citations != nil {
+			t.Fatalf("message = %+v, want no thinking_blocks or citations", message)
+		}
+	}
+
+	if messages[1].ThinkingBlocks == nil || messages[1].Citations == nil {
+		t.Fatalf("caller messages were modified: %+v", messages[1])
+	}
+}
+
+func TestEmbeddingInputs(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		input   any
+		want    []string
+		wantErr bool
+	}{
+		{name: "string", input: "hello", want: []string{"hello"}},
+		{name: "string array", input: []string{"a", "b"}, want: []string{"a", "b"}},
+		{name: "interface array", input: []interface{}{"a", "b"}, want: []string{"a", "b"}},
+		{name: "token array", input: []interface{}{1, 2, 3}, wantErr: true},
+		{name: "token ids", input: []int{1, 2, 3}, wantErr: true},
+		{name: "nil", input: nil, wantErr: true},
+		{name: "empty string array", input: []string{}, wantErr: true},
+		{name: "empty interface array", input: []interface{}{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			got, err := EmbeddingInputs(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EmbeddingInputs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EmbeddingInputs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
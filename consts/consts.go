@@ -4,6 +4,7 @@ const (
 	CORP_OPENAI         = "OpenAI"
 	CORP_AZURE          = "Azure"
 	CORP_BAIDU          = "Baidu"
+	CORP_BAIDU_QIANFAN  = "Baidu-Qianfan"
 	CORP_XFYUN          = "Xfyun"
 	CORP_ALIYUN         = "Aliyun"
 	CORP_ZHIPUAI        = "ZhipuAI"
@@ -29,6 +30,20 @@ const (
 const (
 	DELTA_TYPE_TEXT       = "text_delta"
 	DELTA_TYPE_INPUT_JSON = "input_json_delta"
+	DELTA_TYPE_THINKING   = "thinking_delta"
+	DELTA_TYPE_SIGNATURE  = "signature_delta"
+)
+
+const (
+	EVENT_TYPE_CONTENT_BLOCK_STOP = "content_block_stop"
+)
+
+const (
+	CONTENT_TYPE_TEXT              = "text"
+	CONTENT_TYPE_TOOL_USE          = "tool_use"
+	CONTENT_TYPE_TOOL_RESULT       = "tool_result"
+	CONTENT_TYPE_THINKING          = "thinking"
+	CONTENT_TYPE_REDACTED_THINKING = "redacted_thinking"
 )
 
 const (
@@ -37,6 +52,70 @@ const (
 	COMPLETION_STREAM_OBJECT = "chat.completion.chunk"
 )
 
+const (
+	BATCH_STATUS_VALIDATING  = "validating"
+	BATCH_STATUS_IN_PROGRESS = "in_progress"
+	BATCH_STATUS_FINALIZING  = "finalizing"
+	BATCH_STATUS_COMPLETED   = "completed"
+	BATCH_STATUS_FAILED      = "failed"
+	BATCH_STATUS_EXPIRED     = "expired"
+	BATCH_STATUS_CANCELING   = "canceling"
+	BATCH_STATUS_CANCELLING  = "cancelling"
+	BATCH_STATUS_CANCELLED   = "cancelled"
+	BATCH_STATUS_ENDED       = "ended"
+)
+
+const (
+	BATCH_RESULT_SUCCEEDED = "succeeded"
+	BATCH_RESULT_ERRORED   = "errored"
+	BATCH_RESULT_CANCELED  = "canceled"
+	BATCH_RESULT_EXPIRED   = "expired"
+)
+
+const (
+	RESPONSES_EVENT_CREATED                  = "response.created"
+	RESPONSES_EVENT_OUTPUT_ITEM_ADDED        = "response.output_item.added"
+	RESPONSES_EVENT_OUTPUT_TEXT_DELTA        = "response.output_text.delta"
+	RESPONSES_EVENT_REFUSAL_DELTA            = "response.refusal.delta"
+	RESPONSES_EVENT_REASONING_SUMMARY        = "response.reasoning_summary_text.delta"
+	RESPONSES_EVENT_FUNCTION_CALL_ARGS       = "response.function_call_arguments.delta"
+	RESPONSES_EVENT_COMPLETED                = "response.completed"
+	RESPONSES_EVENT_INCOMPLETE               = "response.incomplete"
+	RESPONSES_EVENT_FAILED                   = "response.failed"
+	RESPONSES_EVENT_ERROR                    = "error"
+	RESPONSES_ITEM_TYPE_MESSAGE              = "message"
+	RESPONSES_ITEM_TYPE_FUNCTION_CALL        = "function_call"
+	RESPONSES_ITEM_TYPE_FUNCTION_CALL_OUTPUT = "function_call_output"
+	RESPONSES_ITEM_TYPE_REASONING            = "reasoning"
+)
+
+const (
+	// Realtime客户端事件
+	REALTIME_EVENT_SESSION_UPDATE             = "session.update"
+	REALTIME_EVENT_INPUT_AUDIO_BUFFER_APPEND  = "input_audio_buffer.append"
+	REALTIME_EVENT_INPUT_AUDIO_BUFFER_COMMIT  = "input_audio_buffer.commit"
+	REALTIME_EVENT_INPUT_AUDIO_BUFFER_CLEAR   = "input_audio_buffer.clear"
+	REALTIME_EVENT_CONVERSATION_ITEM_CREATE   = "conversation.item.create"
+	REALTIME_EVENT_CONVERSATION_ITEM_TRUNCATE = "conversation.item.truncate"
+	REALTIME_EVENT_CONVERSATION_ITEM_DELETE   = "conversation.item.delete"
+	REALTIME_EVENT_RESPONSE_CREATE            = "response.create"
+	REALTIME_EVENT_RESPONSE_CANCEL            = "response.cancel"
+	// Realtime服务端事件
+	REALTIME_EVENT_ERROR                       = "error"
+	REALTIME_EVENT_SESSION_CREATED             = "session.created"
+	REALTIME_EVENT_SESSION_UPDATED             = "session.updated"
+	REALTIME_EVENT_CONVERSATION_ITEM_CREATED   = "conversation.item.created"
+	REALTIME_EVENT_SPEECH_STARTED              = "input_audio_buffer.speech_started"
+	REALTIME_EVENT_SPEECH_STOPPED              = "input_audio_buffer.speech_stopped"
+	REALTIME_EVENT_RESPONSE_CREATED            = "response.created"
+	REALTIME_EVENT_RESPONSE_TEXT_DELTA         = "response.text.delta"
+	REALTIME_EVENT_RESPONSE_AUDIO_DELTA        = "response.audio.delta"
+	REALTIME_EVENT_RESPONSE_AUDIO_TRANSCRIPT   = "response.audio_transcript.delta"
+	REALTIME_EVENT_RESPONSE_FUNCTION_ARGUMENTS = "response.function_call_arguments.done"
+	REALTIME_EVENT_RESPONSE_DONE               = "response.done"
+	REALTIME_EVENT_RATE_LIMITS_UPDATED         = "rate_limits.updated"
+)
+
 var MIME_TYPE_MAP = map[string]string{
 	"pdf":  "application/pdf",
 	"js":   "application/x-javascript",
@@ -53,3 +132,30 @@ var MIME_TYPE_MAP = map[string]string{
 	"xml":  "text/xml",
 	"rtf":  "text/rtf",
 }
+
+const (
+	MIDJOURNEY_CODE_SUCCESS       = 1
+	MIDJOURNEY_CODE_NOT_FOUND     = 3
+	MIDJOURNEY_CODE_VALIDATION    = 4
+	MIDJOURNEY_CODE_FAILURE       = 9
+	MIDJOURNEY_CODE_EXISTED       = 21
+	MIDJOURNEY_CODE_IN_QUEUE      = 22
+	MIDJOURNEY_CODE_QUEUE_REJECT  = 23
+	MIDJOURNEY_CODE_BANNED_PROMPT = 24
+)
+
+const (
+	MIDJOURNEY_STATUS_NOT_START   = "NOT_START"
+	MIDJOURNEY_STATUS_SUBMITTED   = "SUBMITTED"
+	MIDJOURNEY_STATUS_MODAL       = "MODAL"
+	MIDJOURNEY_STATUS_IN_PROGRESS = "IN_PROGRESS"
+	MIDJOURNEY_STATUS_FAILURE     = "FAILURE"
+	MIDJOURNEY_STATUS_SUCCESS     = "SUCCESS"
+	MIDJOURNEY_STATUS_CANCEL      = "CANCEL"
+)
+
+const (
+	MIDJOURNEY_ACTION_UPSCALE   = "UPSCALE"
+	MIDJOURNEY_ACTION_VARIATION = "VARIATION"
+	MIDJOURNEY_ACTION_REROLL    = "REROLL"
+)
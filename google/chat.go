@@ -28,85 +28,19 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 		logger.Infof(ctx, "ChatCompletion Google model: %s totalTime: %d ms", request.Model, res.TotalTime)
 	}()
 
-	var messages []model.ChatCompletionMessage
-	if c.isSupportSystemRole != nil {
-		messages = common.HandleMessages(request.Messages, *c.isSupportSystemRole)
-	} else {
-		messages = common.HandleMessages(request.Messages, false)
-	}
-
-	contents := make([]model.Content, 0)
-	for _, message := range messages {
-
-		role := message.Role
-
-		if role == consts.ROLE_ASSISTANT {
-			role = consts.ROLE_MODEL
-		}
-
-		parts := make([]model.Part, 0)
-
-		if contents, ok := message.Content.([]interface{}); ok {
-
-			for _, value := range contents {
-
-				if content, ok := value.(map[string]interface{}); ok {
-
-					if content["type"] == "image_url" {
-
-						if imageUrl, ok := content["image_url"].(map[string]interface{}); ok {
-
-							mimeType, data := common.GetMime(gconv.String(imageUrl["url"]))
-
-							parts = append(parts, model.Part{
-								InlineData: &model.InlineData{
-									MimeType: mimeType,
-									Data:     data,
-								},
-							})
-						}
-
-					} else if content["type"] == "video_url" {
-						if videoUrl, ok := content["video_url"].(map[string]interface{}); ok {
-
-							url := gconv.String(videoUrl["url"])
-							format := gconv.String(videoUrl["format"])
-
-							parts = append(parts, model.Part{
-								FileData: &model.FileData{
-									MimeType: "video/" + format,
-									FileUri:  url,
-								},
-							})
-						}
-					} else {
-						parts = append(parts, model.Part{
-							Text: gconv.String(content["text"]),
-						})
-					}
-				}
-			}
-
-		} else {
-			parts = append(parts, model.Part{
-				Text: gconv.String(message.Content),
-			})
-		}
-
-		contents = append(contents, model.Content{
-			Role:  role,
-			Parts: parts,
-		})
-	}
+	systemInstruction, contents := c.convContents(request.Messages)
 
 	chatCompletionReq := model.GoogleChatCompletionReq{
-		Contents: contents,
+		SystemInstruction: systemInstruction,
+		Contents:          contents,
 		GenerationConfig: model.GenerationConfig{
 			MaxOutputTokens: request.MaxTokens,
 			Temperature:     request.Temperature,
 			TopP:            request.TopP,
 		},
-		Tools: request.Tools,
+		Tools:          convTools(request.Tools, request.Functions),
+		ToolConfig:     convToolConfig(request.ToolChoice),
+		SafetySettings: request.SafetySettings,
 	}
 
 	chatCompletionRes := new(model.GoogleChatCompletionRes)
@@ -115,7 +49,7 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 		return
 	}
 
-	if chatCompletionRes.Error.Code != 0 || chatCompletionRes.Candidates[0].FinishReason != "STOP" {
+	if chatCompletionRes.Error.Code != 0 {
 		logger.Errorf(ctx, "ChatCompletion Google model: %s, chatCompletionRes: %s", request.Model, gjson.MustEncodeString(chatCompletionRes))
 
 		err = c.apiErrorHandler(chatCompletionRes)
@@ -125,121 +59,95 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 	}
 
 	res = model.ChatCompletionResponse{
-		ID:      consts.COMPLETION_ID_PREFIX + grand.S(29),
-		Object:  consts.COMPLETION_OBJECT,
-		Created: gtime.Timestamp(),
-		Model:   request.Model,
-		Usage: &model.Usage{
+		ID:             consts.COMPLETION_ID_PREFIX + grand.S(29),
+		Object:         consts.COMPLETION_OBJECT,
+		Created:        gtime.Timestamp(),
+		Model:          request.Model,
+		PromptFeedback: chatCompletionRes.PromptFeedback,
+	}
+
+	if chatCompletionRes.UsageMetadata != nil {
+		res.Usage = &model.Usage{
 			PromptTokens:     chatCompletionRes.UsageMetadata.PromptTokenCount,
 			CompletionTokens: chatCompletionRes.UsageMetadata.CandidatesTokenCount,
 			TotalTokens:      chatCompletionRes.UsageMetadata.TotalTokenCount,
-		},
+		}
 	}
 
-	for i, part := range chatCompletionRes.Candidates[0].Content.Parts {
+	// 提示词被拦截时不会返回候选结果
+	if len(chatCompletionRes.Candidates) == 0 {
+
+		logger.Infof(ctx, "ChatCompletion Google model: %s, promptFeedback: %s", request.Model, gjson.MustEncodeString(chatCompletionRes.PromptFeedback))
+
 		res.Choices = append(res.Choices, model.ChatCompletionChoice{
-			Index: i,
 			Message: &model.ChatCompletionMessage{
 				Role:    consts.ROLE_ASSISTANT,
-				Content: part.Text,
+				Content: "",
 			},
-			FinishReason: openai.FinishReasonStop,
+			FinishReason: openai.FinishReasonContentFilter,
 		})
-	}
 
-	return res, nil
-}
+		return res, nil
+	}
 
-func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCompletionRequest) (responseChan chan *model.ChatCompletionResponse, err error) {
+	candidate := chatCompletionRes.Candidates[0]
+	finishReason := convFinishReason(candidate.FinishReason)
 
-	logger.Infof(ctx, "ChatCompletionStream Google model: %s start", request.Model)
-
-	now := gtime.TimestampMilli()
-	defer func() {
-		if err != nil {
-			logger.Infof(ctx, "ChatCompletionStream Google model: %s totalTime: %d ms", request.Model, gtime.TimestampMilli()-now)
-		}
-	}()
-
-	var messages []model.ChatCompletionMessage
-	if c.isSupportSystemRole != nil {
-		messages = common.HandleMessages(request.Messages, *c.isSupportSystemRole)
-	} else {
-		messages = common.HandleMessages(request.Messages, false)
+	// 文本与函数调用合并为同一条assistant消息
+	message := &model.ChatCompletionMessage{
+		Role: consts.ROLE_ASSISTANT,
 	}
 
-	contents := make([]model.Content, 0)
-	for _, message := range messages {
+	content := ""
+	for _, part := range candidate.Content.Parts {
 
-		role := message.Role
-
-		if role == consts.ROLE_ASSISTANT {
-			role = consts.ROLE_MODEL
+		if part.FunctionCall != nil {
+			message.ToolCalls = append(message.ToolCalls, convToolCall(part.FunctionCall))
+			continue
 		}
 
-		parts := make([]model.Part, 0)
-
-		if contents, ok := message.Content.([]interface{}); ok {
-
-			for _, value := range contents {
+		content += part.Text
+	}
 
-				if content, ok := value.(map[string]interface{}); ok {
+	message.Content = content
 
-					if content["type"] == "image_url" {
-
-						if imageUrl, ok := content["image_url"].(map[string]interface{}); ok {
-
-							mimeType, data := common.GetMime(gconv.String(imageUrl["url"]))
+	if len(message.ToolCalls) > 0 {
+		finishReason = openai.FinishReasonToolCalls
+	}
 
-							parts = append(parts, model.Part{
-								InlineData: &model.InlineData{
-									MimeType: mimeType,
-									Data:     data,
-								},
-							})
-						}
+	res.Choices = append(res.Choices, model.ChatCompletionChoice{
+		Message:       message,
+		FinishReason:  finishReason,
+		SafetyRatings: candidate.SafetyRatings,
+	})
 
-					} else if content["type"] == "video_url" {
-						if videoUrl, ok := content["video_url"].(map[string]interface{}); ok {
+	return res, nil
+}
 
-							url := gconv.String(videoUrl["url"])
-							format := gconv.String(videoUrl["format"])
+func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCompletionRequest) (responseChan chan *model.ChatCompletionResponse, err error) {
 
-							parts = append(parts, model.Part{
-								FileData: &model.FileData{
-									MimeType: "video/" + format,
-									FileUri:  url,
-								},
-							})
-						}
-					} else {
-						parts = append(parts, model.Part{
-							Text: gconv.String(content["text"]),
-						})
-					}
-				}
-			}
+	logger.Infof(ctx, "ChatCompletionStream Google model: %s start", request.Model)
 
-		} else {
-			parts = append(parts, model.Part{
-				Text: gconv.String(message.Content),
-			})
+	now := gtime.TimestampMilli()
+	defer func() {
+		if err != nil {
+			logger.Infof(ctx, "ChatCompletionStream Google model: %s totalTime: %d ms", request.Model, gtime.TimestampMilli()-now)
 		}
+	}()
 
-		contents = append(contents, model.Content{
-			Role:  role,
-			Parts: parts,
-		})
-	}
+	systemInstruction, contents := c.convContents(request.Messages)
 
 	chatCompletionReq := model.GoogleChatCompletionReq{
-		Contents: contents,
+		SystemInstruction: systemInstruction,
+		Contents:          contents,
 		GenerationConfig: model.GenerationConfig{
 			MaxOutputTokens: request.MaxTokens,
 			Temperature:     request.Temperature,
 			TopP:            request.TopP,
 		},
-		Tools: request.Tools,
+		Tools:          convTools(request.Tools, request.Functions),
+		ToolConfig:     convToolConfig(request.ToolChoice),
+		SafetySettings: request.SafetySettings,
 	}
 
 	stream, err := util.SSEClient(ctx, fmt.Sprintf("%s:streamGenerateContent?alt=sse&key=%s", c.baseURL+c.path, c.key), nil, chatCompletionReq, c.proxyURL, c.requestErrorHandler)
@@ -264,9 +172,11 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 		}()
 
 		var (
-			usage   *model.Usage
-			created = gtime.Timestamp()
-			id      = consts.COMPLETION_ID_PREFIX + grand.S(29)
+			usage         *model.Usage
+			created       = gtime.Timestamp()
+			id            = consts.COMPLETION_ID_PREFIX + grand.S(29)
+			toolCallIndex int
+			finishReason  openai.FinishReason
 		)
 
 		for {
@@ -292,6 +202,14 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 			if errors.Is(err, io.EOF) {
 				logger.Infof(ctx, "ChatCompletionStream Google model: %s finished", request.Model)
 
+				if finishReason == "" {
+					finishReason = openai.FinishReasonStop
+				}
+
+				if toolCallIndex > 0 && finishReason == openai.FinishReasonStop {
+					finishReason = openai.FinishReasonToolCalls
+				}
+
 				end := gtime.TimestampMilli()
 				responseChan <- &model.ChatCompletionResponse{
 					ID:      id,
@@ -300,7 +218,7 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 					Model:   request.Model,
 					Choices: []model.ChatCompletionChoice{{
 						Delta:        &model.ChatCompletionStreamChoiceDelta{},
-						FinishReason: openai.FinishReasonStop,
+						FinishReason: finishReason,
 					}},
 					Usage:     usage,
 					ConnTime:  duration - now,
@@ -359,21 +277,58 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 			}
 
 			response := &model.ChatCompletionResponse{
-				ID:      id,
-				Object:  consts.COMPLETION_STREAM_OBJECT,
-				Created: created,
-				Model:   request.Model,
+				ID:             id,
+				Object:         consts.COMPLETION_STREAM_OBJECT,
+				Created:        created,
+				Model:          request.Model,
+				PromptFeedback: chatCompletionRes.PromptFeedback,
+				Usage:          usage,
+				ConnTime:       duration - now,
+			}
 
-				Usage:    usage,
-				ConnTime: duration - now,
+			if chatCompletionRes.PromptFeedback != nil && chatCompletionRes.PromptFeedback.BlockReason != "" {
+				finishReason = openai.FinishReasonContentFilter
 			}
 
 			for _, candidate := range chatCompletionRes.Candidates {
+
+				delta := &model.ChatCompletionStreamChoiceDelta{
+					Role: consts.ROLE_ASSISTANT,
+				}
+
+				for _, part := range candidate.Content.Parts {
+
+					if part.FunctionCall != nil {
+
+						index := toolCallIndex
+						toolCallIndex++
+
+						toolCall := convToolCall(part.FunctionCall)
+						toolCall.Index = &index
+
+						delta.ToolCalls = append(delta.ToolCalls, toolCall)
+
+						continue
+					}
+
+					delta.Content += part.Text
+				}
+
+				if candidate.FinishReason != "" {
+					finishReason = convFinishReason(candidate.FinishReason)
+				}
+
+				response.Choices = append(response.Choices, model.ChatCompletionChoice{
+					Index:         candidate.Index,
+					Delta:         delta,
+					SafetyRatings: candidate.SafetyRatings,
+				})
+			}
+
+			if len(response.Choices) == 0 {
 				response.Choices = append(response.Choices, model.ChatCompletionChoice{
-					Index: candidate.Index,
 					Delta: &model.ChatCompletionStreamChoiceDelta{
-						Role:    consts.ROLE_ASSISTANT,
-						Content: candidate.Content.Parts[0].Text,
+						Role: consts.ROLE_ASSISTANT,
 					},
 				})
 			}
@@ -391,3 +346,328 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 
 	return responseChan, nil
 }
+
+func (c *Client) convContents(messages []model.ChatCompletionMessage) (systemInstruction *model.Content, contents []model.Content) {
+
+	// 默认使用原生的systemInstruction, 仅在明确不支持时转为user消息
+	isSupportSystemRole := true
+	if c.isSupportSystemRole != nil {
+		isSupportSystemRole = *c.isSupportSystemRole
+	}
+
+	toolCallNames := make(map[string]string)
+	for _, message := range messages {
+		for _, toolCall := range message.ToolCalls {
+			toolCallNames[toolCall.ID] = toolCall.Function.Name
+		}
+	}
+
+	contents = make([]model.Content, 0)
+	for _, message := range messages {
+
+		role := message.Role
+		parts := make([]model.Part, 0)
+
+		if message.ToolCallID != "" || role == consts.ROLE_TOOL || role == consts.ROLE_FUNCTION {
+
+			role = consts.ROLE_USER
+
+			parts = append(parts, convFunctionResponsePart(message, toolCallNames))
+
+		} else if contents, ok := message.Content.([]interface{}); ok {
+
+			for _, value := range contents {
+
+				if content, ok := value.(map[string]interface{}); ok {
+
+					if content["type"] == "image_url" {
+
+						if imageUrl, ok := content["image_url"].(map[string]interface{}); ok {
+
+							mimeType, data := common.GetMime(gconv.String(imageUrl["url"]))
+
+							parts = append(parts, model.Part{
+								InlineData: &model.InlineData{
+									MimeType: mimeType,
+									Data:     data,
+								},
+							})
+						}
+
+					} else if content["type"] == "video_url" {
+						if videoUrl, ok := content["video_url"].(map[string]interface{}); ok {
+
+							url := gconv.String(videoUrl["url"])
+							format := gconv.String(videoUrl["format"])
+
+							parts = append(parts, model.Part{
+								FileData: &model.FileData{
+									MimeType: "video/" + format,
+									FileUri:  url,
+								},
+							})
+						}
+					} else if text := gconv.String(content["text"]); text != "" {
+						parts = append(parts, model.Part{
+							Text: text,
+						})
+					}
+				}
+			}
+
+		} else if text := gconv.String(message.Content); text != "" {
+			parts = append(parts, model.Part{
+				Text: text,
+			})
+		}
+
+		parts = append(parts, convFunctionCallParts(message.ToolCalls)...)
+
+		if message.FunctionCall != nil {
+			parts = append(parts, convFunctionCallParts([]openai.ToolCall{{Function: *message.FunctionCall}})...)
+		}
+
+		if len(parts) == 0 {
+			continue
+		}
+
+		if role == consts.ROLE_SYSTEM {
+
+			if isSupportSystemRole {
+
+				if systemInstruction == nil {
+					systemInstruction = new(model.Content)
+				}
+
+				systemInstruction.Parts = append(systemInstruction.Parts, parts...)
+
+				continue
+			}
+
+			role = consts.ROLE_USER
+		}
+
+		if role == consts.ROLE_ASSISTANT || role == consts.ROLE_MODEL {
+			role = consts.ROLE_MODEL
+		} else {
+			role = consts.ROLE_USER
+		}
+
+		// 合并连续相同角色的消息, 避免丢弃历史消息
+		if len(contents) > 0 && contents[len(contents)-1].Role == role {
+			contents[len(contents)-1].Parts = append(contents[len(contents)-1].Parts, parts...)
+			continue
+		}
+
+		contents = append(contents, model.Content{
+			Role:  role,
+			Parts: parts,
+		})
+	}
+
+	return systemInstruction, contents
+}
+
+func convFinishReason(finishReason openai.FinishReason) openai.FinishReason {
+
+	switch finishReason {
+	case "MAX_TOKENS":
+		return openai.FinishReasonLength
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return openai.FinishReasonContentFilter
+	}
+
+	return openai.FinishReasonStop
+}
+
+func convTools(tools any, functions []openai.FunctionDefinition) any {
+
+	if tools == nil && len(functions) == 0 {
+		return nil
+	}
+
+	var (
+		googleTools          = make([]any, 0)
+		functionDeclarations = make([]model.FunctionDeclaration, 0)
+	)
+
+	for _, function := range functions {
+
+		parameters := make(map[string]interface{})
+		if err := gjson.Unmarshal(gjson.MustEncode(function.Parameters), &parameters); err != nil {
+			parameters = nil
+		}
+
+		functionDeclarations = append(functionDeclarations, model.FunctionDeclaration{
+			Name:        function.Name,
+			Description: function.Description,
+			Parameters:  convSchema(parameters),
+		})
+	}
+
+	values := make([]map[string]interface{}, 0)
+	if tools != nil {
+		if err := gjson.Unmarshal(gjson.MustEncode(tools), &values); err != nil {
+			return tools
+		}
+	}
+
+	for _, tool := range values {
+
+		if tool["type"] != string(openai.ToolTypeFunction) {
+			// 原生工具, 如: googleSearch、codeExecution
+			googleTools = append(googleTools, tool)
+			continue
+		}
+
+		if function, ok := tool["function"].(map[string]interface{}); ok {
+
+			functionDeclaration := model.FunctionDeclaration{
+				Name:        gconv.String(function["name"]),
+				Description: gconv.String(function["description"]),
+			}
+
+			if parameters, ok := function["parameters"].(map[string]interface{}); ok {
+				functionDeclaration.Parameters = convSchema(parameters)
+			}
+
+			functionDeclarations = append(functionDeclarations, functionDeclaration)
+		}
+	}
+
+	if len(functionDeclarations) > 0 {
+		googleTools = append(googleTools, model.GoogleTool{
+			FunctionDeclarations: functionDeclarations,
+		})
+	}
+
+	if len(googleTools) == 0 {
+		return nil
+	}
+
+	return googleTools
+}
+
+// Google仅支持OpenAPI Schema的子集, 需移除不支持的字段
+func convSchema(schema map[string]interface{}) map[string]interface{} {
+
+	if len(schema) == 0 {
+		return nil
+	}
+
+	delete(schema, "$schema")
+	delete(schema, "additionalProperties")
+	delete(schema, "strict")
+
+	for _, value := range schema {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			convSchema(v)
+		case []interface{}:
+			for _, item := range v {
+				if m, ok := item.(map[string]interface{}); ok {
+					convSchema(m)
+				}
+			}
+		}
+	}
+
+	return schema
+}
+
+func convToolConfig(toolChoice any) *model.ToolConfig {
+
+	if toolChoice == nil {
+		return nil
+	}
+
+	functionCallingConfig := &model.FunctionCallingConfig{
+		Mode: "AUTO",
+	}
+
+	if choice, ok := toolChoice.(string); ok {
+		switch choice {
+		case "none":
+			functionCallingConfig.Mode = "NONE"
+		case "required":
+			functionCallingConfig.Mode = "ANY"
+		}
+	} else {
+
+		tool := new(openai.ToolChoice)
+		if err := gjson.Unmarshal(gjson.MustEncode(toolChoice), &tool); err == nil && tool.Function.Name != "" {
+			functionCallingConfig.Mode = "ANY"
+			functionCallingConfig.AllowedFunctionNames = []string{tool.Function.Name}
+		}
+	}
+
+	return &model.ToolConfig{
+		FunctionCallingConfig: functionCallingConfig,
+	}
+}
+
+func convFunctionCallParts(toolCalls []openai.ToolCall) []model.Part {
+
+	parts := make([]model.Part, 0)
+
+	for _, toolCall := range toolCalls {
+
+		args := make(map[string]interface{})
+		if toolCall.Function.Arguments != "" {
+			if err := gjson.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
+				args = make(map[string]interface{})
+			}
+		}
+
+		parts = append(parts, model.Part{
+			FunctionCall: &model.FunctionCall{
+				Name: toolCall.Function.Name,
+				Args: args,
+			},
+		})
+	}
+
+	return parts
+}
+
+func convFunctionResponsePart(message model.ChatCompletionMessage, toolCallNames map[string]string) model.Part {
+
+	name := toolCallNames[message.ToolCallID]
+	if name == "" {
+		name = message.Name
+	}
+
+	content := gconv.String(message.Content)
+
+	// functionResponse.response 必须是对象
+	response := make(map[string]interface{})
+	if err := gjson.Unmarshal([]byte(content), &response); err != nil || len(response) == 0 {
+		response = map[string]interface{}{
+			"content": content,
+		}
+	}
+
+	return model.Part{
+		FunctionResponse: &model.FunctionResponse{
+			Name:     name,
+			Response: response,
+		},
+	}
+}
+
+func convToolCall(functionCall *model.FunctionCall) openai.ToolCall {
+
+	arguments := "{}"
+	if functionCall.Args != nil {
+		arguments = gjson.MustEncodeString(functionCall.Args)
+	}
+
+	return openai.ToolCall{
+		ID:   "call_" + grand.S(24),
+		Type: openai.ToolTypeFunction,
+		Function: openai.FunctionCall{
+			Name:      functionCall.Name,
+			Arguments: arguments,
+		},
+	}
+}
@@ -28,7 +28,7 @@ func (c *Client) ChatCompletionOfficial(ctx context.Context, data []byte) (res m
 		return res, err
 	}
 
-	if res.Error.Code != 0 || res.Candidates[0].FinishReason != "STOP" {
+	if res.Error.Code != 0 {
 		logger.Errorf(ctx, "ChatCompletionOfficial Google model: %s, chatCompletionRes: %s", c.model, gjson.MustEncodeString(res))
 
 		err = c.apiErrorHandler(&res)
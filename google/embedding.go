@@ -2,10 +2,151 @@ package google
 
 import (
 	"context"
+	"fmt"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/iimeta/fastapi-sdk/common"
+	"github.com/iimeta/fastapi-sdk/logger"
 	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
+	"github.com/iimeta/fastapi-sdk/tiktoken"
+	"github.com/iimeta/fastapi-sdk/util"
+	"github.com/iimeta/go-openai"
 )
 
+// batchEmbedContents 单次请求最多支持100条
+const embeddingBatchSize = 100
+
 func (c *Client) Embeddings(ctx context.Context, request model.EmbeddingRequest) (res model.EmbeddingResponse, err error) {
-	//TODO implement me
-	panic("implement me")
+
+	logger.Infof(ctx, "Embeddings Google model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "Embeddings Google model: %s totalTime: %d ms", request.Model, res.TotalTime)
+	}()
+
+	inputs, err := common.EmbeddingInputs(request.Input)
+	if err != nil {
+		logger.Errorf(ctx, "Embeddings Google model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	res = model.EmbeddingResponse{
+		Object: "list",
+		Model:  request.Model,
+		Usage:  new(model.Usage),
+	}
+
+	if len(inputs) == 1 {
+
+		embeddingReq := model.GoogleEmbeddingReq{
+			Model: "models/" + string(request.Model),
+			Content: model.Content{
+				Parts: []model.Part{{
+					Text: inputs[0],
+				}},
+			},
+			TaskType:             request.TaskType,
+			OutputDimensionality: request.Dimensions,
+		}
+
+		embeddingRes := new(model.GoogleEmbeddingRes)
+		if _, err = util.HttpPost(ctx, fmt.Sprintf("%s:embedContent?key=%s", c.baseURL+c.path, c.key), nil, embeddingReq, &embeddingRes, c.proxyURL); err != nil {
+			logger.Errorf(ctx, "Embeddings Google model: %s, error: %v", request.Model, err)
+			return res, err
+		}
+
+		if embeddingRes.Error.Code != 0 || embeddingRes.Embedding == nil {
+			logger.Errorf(ctx, "Embeddings Google model: %s, embeddingRes: %s", request.Model, gjson.MustEncodeString(embeddingRes))
+
+			err = sdkerr.NewApiError(500, embeddingRes.Error.Code, gjson.MustEncodeString(embeddingRes), "api_error", "")
+			logger.Errorf(ctx, "Embeddings Google model: %s, error: %v", request.Model, err)
+
+			return res, err
+		}
+
+		res.Data = append(res.Data, openai.Embedding{
+			Object:    "embedding",
+			Embedding: embeddingRes.Embedding.Values,
+		})
+
+	} else {
+
+		for i := 0; i < len(inputs); i += embeddingBatchSize {
+
+			batchEmbeddingReq := model.GoogleBatchEmbeddingReq{
+				Requests: make([]model.GoogleEmbeddingReq, 0),
+			}
+
+			batchInputs := inputs[i:min(i+embeddingBatchSize, len(inputs))]
+
+			for _, input := range batchInputs {
+				batchEmbeddingReq.Requests = append(batchEmbeddingReq.Requests, model.GoogleEmbeddingReq{
+					Model: "models/" + string(request.Model),
+					Content: model.Content{
+						Parts: []model.Part{{
+							Text: input,
+						}},
+					},
+					TaskType:             request.TaskType,
+					OutputDimensionality: request.Dimensions,
+				})
+			}
+
+			embeddingRes := new(model.GoogleEmbeddingRes)
+			if _, err = util.HttpPost(ctx, fmt.Sprintf("%s:batchEmbedContents?key=%s", c.baseURL+c.path, c.key), nil, batchEmbeddingReq, &embeddingRes, c.proxyURL); err != nil {
+				logger.Errorf(ctx, "Embeddings Google model: %s, error: %v", request.Model, err)
+				return res, err
+			}
+
+			if embeddingRes.Error.Code != 0 {
+				logger.Errorf(ctx, "Embeddings Google model: %s, embeddingRes: %s", request.Model, gjson.MustEncodeString(embeddingRes))
+
+				err = sdkerr.NewApiError(500, embeddingRes.Error.Code, gjson.MustEncodeString(embeddingRes), "api_error", "")
+				logger.Errorf(ctx, "Embeddings Google model: %s, error: %v", request.Model, err)
+
+				return res, err
+			}
+
+			// 返回的向量数量与输入不一致时无法对应index
+			if len(embeddingRes.Embeddings) != len(batchInputs) {
+				logger.Errorf(ctx, "Embeddings Google model: %s, embeddingRes: %s", request.Model, gjson.MustEncodeString(embeddingRes))
+
+				err = sdkerr.NewApiError(500, "embedding_count_mismatch", fmt.Sprintf("Expected %d embeddings, got %d.", len(batchInputs), len(embeddingRes.Embeddings)), "api_error", "")
+				logger.Errorf(ctx, "Embeddings Google model: %s, error: %v", request.Model, err)
+
+				return res, err
+			}
+
+			for _, embedding := range embeddingRes.Embeddings {
+				res.Data = append(res.Data, openai.Embedding{
+					Object:    "embedding",
+					Embedding: embedding.Values,
+					Index:     len(res.Data),
+				})
+			}
+		}
+	}
+
+	logger.Infof(ctx, "Embeddings Google model: %s finished", request.Model)
+
+	// Google不返回token用量, 按输入内容估算
+	tokenModel := string(request.Model)
+	if !tiktoken.IsEncodingForModel(tokenModel) {
+		tokenModel = string(openai.AdaEmbeddingV2)
+	}
+
+	for _, input := range inputs {
+		if numTokens, err := tiktoken.NumTokensFromString(tokenModel, input); err != nil {
+			logger.Errorf(ctx, "Embeddings Google model: %s, NumTokensFromString error: %v", request.Model, err)
+		} else {
+			res.Usage.PromptTokens += numTokens
+		}
+	}
+
+	res.Usage.TotalTokens = res.Usage.PromptTokens
+
+	return res, nil
 }
@@ -2,10 +2,291 @@ package google
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/iimeta/fastapi-sdk/common"
+	"github.com/iimeta/fastapi-sdk/consts"
+	"github.com/iimeta/fastapi-sdk/logger"
 	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
+	"github.com/iimeta/fastapi-sdk/util"
+	"math"
+	"net/http"
 )
 
+// Imagen支持的宽高比
+var aspectRatios = []string{"1:1", "3:4", "4:3", "9:16", "16:9"}
+
 func (c *Client) Image(ctx context.Context, request model.ImageRequest) (res model.ImageResponse, err error) {
-	//TODO implement me
-	panic("implement me")
+
+	logger.Infof(ctx, "Image Google model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "Image Google model: %s totalTime: %d ms", request.Model, gtime.TimestampMilli()-now)
+	}()
+
+	res = model.ImageResponse{
+		Created: gtime.Timestamp(),
+	}
+
+	if gstr.HasPrefix(request.Model, "imagen") {
+
+		imageReq := model.GoogleImageReq{
+			Instances: []model.ImageInstance{{
+				Prompt: request.Prompt,
+			}},
+			Parameters: model.ImageParameters{
+				SampleCount: request.N,
+				AspectRatio: convAspectRatio(request),
+			},
+		}
+
+		imageRes := new(model.GoogleImageRes)
+		if _, err = util.HttpPost(ctx, fmt.Sprintf("%s:predict?key=%s", c.baseURL+c.path, c.key), nil, imageReq, &imageRes, c.proxyURL); err != nil {
+			logger.Errorf(ctx, "Image Google model: %s, error: %v", request.Model, err)
+			return res, err
+		}
+
+		if imageRes.Error.Code != 0 {
+			logger.Errorf(ctx, "Image Google model: %s, imageRes: %s", request.Model, gjson.MustEncodeString(imageRes))
+
+			err = sdkerr.NewApiError(500, imageRes.Error.Code, gjson.MustEncodeString(imageRes), "api_error", "")
+			logger.Errorf(ctx, "Image Google model: %s, error: %v", request.Model, err)
+
+			return res, err
+		}
+
+		for _, prediction := range imageRes.Predictions {
+			res.Data = append(res.Data, model.ImageResponseDataInner{
+				B64JSON:       prediction.BytesBase64Encoded,
+				RevisedPrompt: prediction.Prompt,
+			})
+		}
+
+	} else {
+
+		imageReq := model.GoogleChatCompletionReq{
+			Contents: []model.Content{{
+				Role: consts.ROLE_USER,
+				Parts: []model.Part{{
+					Text: request.Prompt,
+				}},
+			}},
+			GenerationConfig: model.GenerationConfig{
+				ResponseModalities: []string{"TEXT", "IMAGE"},
+			},
+		}
+
+		if request.N > 1 {
+			imageReq.GenerationConfig.CandidateCount = request.N
+		}
+
+		if aspectRatio := convAspectRatio(request); aspectRatio != "" {
+			imageReq.GenerationConfig.ImageConfig = &model.ImageConfig{
+				AspectRatio: aspectRatio,
+			}
+		}
+
+		imageRes := new(model.GoogleChatCompletionRes)
+		if _, err = util.HttpPost(ctx, fmt.Sprintf("%s:generateContent?key=%s", c.baseURL+c.path, c.key), nil, imageReq, &imageRes, c.proxyURL); err != nil {
+			logger.Errorf(ctx, "Image Google model: %s, error: %v", request.Model, err)
+			return res, err
+		}
+
+		if imageRes.Error.Code != 0 {
+			logger.Errorf(ctx, "Image Google model: %s, imageRes: %s", request.Model, gjson.MustEncodeString(imageRes))
+
+			err = c.apiErrorHandler(imageRes)
+			logger.Errorf(ctx, "Image Google model: %s, error: %v", request.Model, err)
+
+			return res, err
+		}
+
+		for _, candidate := range imageRes.Candidates {
+
+			var revisedPrompt string
+
+			for _, part := range candidate.Content.Parts {
+
+				if part.InlineData == nil {
+					revisedPrompt += part.Text
+					continue
+				}
+
+				res.Data = append(res.Data, model.ImageResponseDataInner{
+					B64JSON: part.InlineData.Data,
+				})
+			}
+
+			if revisedPrompt != "" && len(res.Data) > 0 {
+				res.Data[len(res.Data)-1].RevisedPrompt = revisedPrompt
+			}
+		}
+	}
+
+	if len(res.Data) == 0 {
+		err = sdkerr.NewApiError(500, "image_generation_failed", "No image was generated, the prompt may have been blocked.", "api_error", "")
+		logger.Errorf(ctx, "Image Google model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	logger.Infof(ctx, "Image Google model: %s finished", request.Model)
+
+	return res, nil
+}
+
+func (c *Client) ImageEdit(ctx context.Context, request model.ImageEditRequest) (res model.ImageResponse, err error) {
+
+	logger.Infof(ctx, "ImageEdit Google model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "ImageEdit Google model: %s totalTime: %d ms", request.Model, gtime.TimestampMilli()-now)
+	}()
+
+	if gstr.HasPrefix(request.Model, "imagen") {
+		err = sdkerr.NewApiError(400, "unsupported_model", fmt.Sprintf("Model %s does not support image edit.", request.Model), "invalid_request_error", "")
+		logger.Errorf(ctx, "ImageEdit Google model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	// Gemini仅支持通过提示词编辑图片, 不支持遮罩
+	if request.Mask != nil {
+		err = sdkerr.NewApiError(400, "unsupported_parameter", fmt.Sprintf("Model %s does not support mask.", request.Model), "invalid_request_error", "mask")
+		logger.Errorf(ctx, "ImageEdit Google model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	_, data, err := common.ReadImageFile(request.Image)
+	if err != nil {
+		logger.Errorf(ctx, "ImageEdit Google model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	imageReq := model.GoogleChatCompletionReq{
+		Contents: []model.Content{{
+			Role: consts.ROLE_USER,
+			Parts: []model.Part{{
+				Text: request.Prompt,
+			}, {
+				InlineData: &model.InlineData{
+					MimeType: http.DetectContentType(data),
+					Data:     base64.StdEncoding.EncodeToString(data),
+				},
+			}},
+		}},
+		GenerationConfig: model.GenerationConfig{
+			ResponseModalities: []string{"TEXT", "IMAGE"},
+		},
+	}
+
+	if request.N > 1 {
+		imageReq.GenerationConfig.CandidateCount = request.N
+	}
+
+	imageRes := new(model.GoogleChatCompletionRes)
+	if _, err = util.HttpPost(ctx, fmt.Sprintf("%s:generateContent?key=%s", c.baseURL+c.path, c.key), nil, imageReq, &imageRes, c.proxyURL); err != nil {
+		logger.Errorf(ctx, "ImageEdit Google model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	if imageRes.Error.Code != 0 {
+		logger.Errorf(ctx, "ImageEdit Google model: %s, imageRes: %s", request.Model, gjson.MustEncodeString(imageRes))
+
+		err = c.apiErrorHandler(imageRes)
+		logger.Errorf(ctx, "ImageEdit Google model: %s, error: %v", request.Model, err)
+
+		return res, err
+	}
+
+	res.Created = gtime.Timestamp()
+
+	for _, candidate := range imageRes.Candidates {
+
+		var revisedPrompt string
+
+		for _, part := range candidate.Content.Parts {
+
+			if part.InlineData == nil {
+				revisedPrompt += part.Text
+				continue
+			}
+
+			res.Data = append(res.Data, model.ImageResponseDataInner{
+				B64JSON: part.InlineData.Data,
+			})
+		}
+
+		if revisedPrompt != "" && len(res.Data) > 0 {
+			res.Data[len(res.Data)-1].RevisedPrompt = revisedPrompt
+		}
+	}
+
+	if len(res.Data) == 0 {
+		err = sdkerr.NewApiError(500, "image_edit_failed", "No image was generated, the prompt may have been blocked.", "api_error", "")
+		logger.Errorf(ctx, "ImageEdit Google model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	logger.Infof(ctx, "ImageEdit Google model: %s finished", request.Model)
+
+	return res, nil
+}
+
+func (c *Client) ImageVariation(ctx context.Context, request model.ImageVariationRequest) (res model.ImageResponse, err error) {
+	return res, sdkerr.NewApiError(400, "unsupported_operation", "Google does not support image variation.", "invalid_request_error", "")
+}
+
+func convAspectRatio(request model.ImageRequest) string {
+
+	if request.AspectRatio != "" {
+		return request.AspectRatio
+	}
+
+	if request.Size == "" {
+		return ""
+	}
+
+	var size []string
+	for _, sep := range []string{`×`, `x`, `X`, `*`, `:`} {
+		if size = gstr.Split(request.Size, sep); len(size) == 2 {
+			break
+		}
+	}
+
+	if len(size) != 2 {
+		return ""
+	}
+
+	width := gconv.Float64(size[0])
+	height := gconv.Float64(size[1])
+
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+
+	var (
+		aspectRatio = aspectRatios[0]
+		minDiff     = math.MaxFloat64
+	)
+
+	// 取最接近的宽高比
+	for _, ratio := range aspectRatios {
+
+		value := gstr.Split(ratio, ":")
+
+		if diff := math.Abs(gconv.Float64(value[0])/gconv.Float64(value[1]) - width/height); diff < minDiff {
+			aspectRatio = ratio
+			minDiff = diff
+		}
+	}
+
+	return aspectRatio
 }
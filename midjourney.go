@@ -2,13 +2,22 @@ package sdk
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/gogf/gf/v2/encoding/gjson"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/os/grpool"
 	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/iimeta/fastapi-sdk/consts"
 	"github.com/iimeta/fastapi-sdk/logger"
 	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
+	"io"
 	"net/http"
+	"time"
 )
 
 type MidjourneyClient struct {
@@ -18,6 +27,7 @@ type MidjourneyClient struct {
 	apiSecretHeader string
 	proxyURL        string
 	method          string
+	notifyHandler   *MidjourneyNotifyHandler
 }
 
 func NewMidjourneyClient(ctx context.Context, baseURL, path, apiSecret, apiSecretHeader, method string, proxyURL ...string) *MidjourneyClient {
@@ -38,6 +48,12 @@ func NewMidjourneyClient(ctx context.Context, baseURL, path, apiSecret, apiSecre
 	return client
 }
 
+// SetNotifyHandler 设置回调处理器, WaitTask会同时等待notifyHook回调
+func (c *MidjourneyClient) SetNotifyHandler(handler *MidjourneyNotifyHandler) *MidjourneyClient {
+	c.notifyHandler = handler
+	return c
+}
+
 func (c *MidjourneyClient) Request(ctx context.Context, data interface{}) (res model.MidjourneyResponse, err error) {
 
 	logger.Infof(ctx, "Midjourney Request data: %s start", gjson.MustEncodeString(data))
@@ -96,3 +112,350 @@ func request(ctx context.Context, method, url, apiSecretHeader, apiSecret string
 
 	return bytes, nil
 }
+
+const (
+	midjourneyImaginePath         = "/mj/submit/imagine"
+	midjourneyChangePath          = "/mj/submit/change"
+	midjourneyActionPath          = "/mj/submit/action"
+	midjourneyBlendPath           = "/mj/submit/blend"
+	midjourneyDescribePath        = "/mj/submit/describe"
+	midjourneyShortenPath         = "/mj/submit/shorten"
+	midjourneyModalPath           = "/mj/submit/modal"
+	midjourneySwapFacePath        = "/mj/insight-face/swap"
+	midjourneyFetchPath           = "/mj/task/%s/fetch"
+	midjourneyListByConditionPath = "/mj/task/list-by-condition"
+)
+
+func (c *MidjourneyClient) Imagine(ctx context.Context, request model.MidjourneyProxyRequest) (res model.MidjourneyProxyResponse, err error) {
+	return c.submit(ctx, "Imagine", midjourneyImaginePath, request)
+}
+
+// Change 对任务执行UPSCALE、VARIATION、REROLL, 需要Action、Index和TaskId
+func (c *MidjourneyClient) Change(ctx context.Context, request model.MidjourneyProxyRequest) (res model.MidjourneyProxyResponse, err error) {
+	return c.submit(ctx, "Change", midjourneyChangePath, request)
+}
+
+// Action 执行任务Buttons中的操作, 需要CustomId和TaskId
+func (c *MidjourneyClient) Action(ctx context.Context, request model.MidjourneyProxyRequest) (res model.MidjourneyProxyResponse, err error) {
+	return c.submit(ctx, "Action", midjourneyActionPath, request)
+}
+
+func (c *MidjourneyClient) Blend(ctx context.Context, request model.MidjourneyProxyRequest) (res model.MidjourneyProxyResponse, err error) {
+	return c.submit(ctx, "Blend", midjourneyBlendPath, request)
+}
+
+func (c *MidjourneyClient) Describe(ctx context.Context, request model.MidjourneyProxyRequest) (res model.MidjourneyProxyResponse, err error) {
+	return c.submit(ctx, "Describe", midjourneyDescribePath, request)
+}
+
+func (c *MidjourneyClient) Shorten(ctx context.Context, request model.MidjourneyProxyRequest) (res model.MidjourneyProxyResponse, err error) {
+	return c.submit(ctx, "Shorten", midjourneyShortenPath, request)
+}
+
+// Modal 提交Action返回MODAL状态的任务, 如局部重绘、自定义变焦
+func (c *MidjourneyClient) Modal(ctx context.Context, request model.MidjourneyProxyRequest) (res model.MidjourneyProxyResponse, err error) {
+	return c.submit(ctx, "Modal", midjourneyModalPath, request)
+}
+
+func (c *MidjourneyClient) SwapFace(ctx context.Context, request model.MidjourneyProxyRequest) (res model.MidjourneyProxyResponse, err error) {
+	return c.submit(ctx, "SwapFace", midjourneySwapFacePath, request)
+}
+
+func (c *MidjourneyClient) Fetch(ctx context.Context, taskId string) (res model.MidjourneyProxyFetchResponse, err error) {
+
+	logger.Infof(ctx, "Midjourney Fetch taskId: %s start", taskId)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "Midjourney Fetch taskId: %s totalTime: %d ms", taskId, res.TotalTime)
+	}()
+
+	if err = c.do(ctx, http.MethodGet, fmt.Sprintf(midjourneyFetchPath, taskId), nil, &res); err != nil {
+		logger.Errorf(ctx, "Midjourney Fetch taskId: %s, error: %v", taskId, err)
+		return res, err
+	}
+
+	if res.Id == "" {
+		err = sdkerr.NewMidjourneyError(consts.MIDJOURNEY_CODE_NOT_FOUND, "task not found", taskId)
+		logger.Errorf(ctx, "Midjourney Fetch taskId: %s, error: %v", taskId, err)
+		return res, err
+	}
+
+	logger.Infof(ctx, "Midjourney Fetch taskId: %s, status: %s, progress: %s finished", taskId, res.Status, res.Progress)
+
+	return res, nil
+}
+
+func (c *MidjourneyClient) ListByCondition(ctx context.Context, ids []string) (res []model.MidjourneyProxyFetchResponse, err error) {
+
+	logger.Infof(ctx, "Midjourney ListByCondition ids: %v start", ids)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		logger.Infof(ctx, "Midjourney ListByCondition ids: %v totalTime: %d ms", ids, gtime.TimestampMilli()-now)
+	}()
+
+	if err = c.do(ctx, http.MethodPost, midjourneyListByConditionPath, model.MidjourneyProxyListByConditionRequest{Ids: ids}, &res); err != nil {
+		logger.Errorf(ctx, "Midjourney ListByCondition ids: %v, error: %v", ids, err)
+		return res, err
+	}
+
+	logger.Infof(ctx, "Midjourney ListByCondition ids: %v finished", ids)
+
+	return res, nil
+}
+
+// submit 提交任务, code非成功时返回sdkerr.MidjourneyError
+func (c *MidjourneyClient) submit(ctx context.Context, action, path string, request model.MidjourneyProxyRequest) (res model.MidjourneyProxyResponse, err error) {
+
+	logger.Infof(ctx, "Midjourney %s start", action)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "Midjourney %s totalTime: %d ms", action, res.TotalTime)
+	}()
+
+	if err = c.do(ctx, http.MethodPost, path, request, &res); err != nil {
+		logger.Errorf(ctx, "Midjourney %s error: %v", action, err)
+		return res, err
+	}
+
+	switch res.Code {
+	case consts.MIDJOURNEY_CODE_SUCCESS, consts.MIDJOURNEY_CODE_EXISTED, consts.MIDJOURNEY_CODE_IN_QUEUE:
+	default:
+		err = sdkerr.NewMidjourneyError(res.Code, res.Description, res.Result)
+		logger.Errorf(ctx, "Midjourney %s error: %v", action, err)
+		return res, err
+	}
+
+	logger.Infof(ctx, "Midjourney %s taskId: %s finished", action, res.Result)
+
+	return res, nil
+}
+
+func (c *MidjourneyClient) do(ctx context.Context, method, path string, data, result interface{}) error {
+
+	url := c.baseURL + path
+
+	logger.Debugf(ctx, "Midjourney do url: %s, data: %s, proxyURL: %v", url, gjson.MustEncodeString(data), c.proxyURL)
+
+	var (
+		client   = g.Client()
+		response *gclient.Response
+		err      error
+	)
+
+	if c.apiSecretHeader != "" {
+		client.SetHeaderMap(g.MapStrStr{c.apiSecretHeader: c.apiSecret})
+	}
+
+	if c.proxyURL != "" {
+		client.SetProxy(c.proxyURL)
+	}
+
+	if method == http.MethodGet {
+		response, err = client.Get(ctx, url, data)
+	} else {
+		response, err = client.ContentJson().Post(ctx, url, data)
+	}
+
+	if response != nil {
+		defer func() {
+			if err := response.Close(); err != nil {
+				logger.Error(ctx, err)
+			}
+		}()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	bytes := response.ReadAll()
+	logger.Debugf(ctx, "Midjourney do url: %s, statusCode: %d, response: %s", url, response.StatusCode, string(bytes))
+
+	if response.StatusCode != http.StatusOK {
+		return sdkerr.NewRequestError(response.StatusCode, errors.New(string(bytes)))
+	}
+
+	if len(bytes) == 0 {
+		return nil
+	}
+
+	return gjson.Unmarshal(bytes, result)
+}
+
+// 轮询时允许连续Fetch失败的次数
+const midjourneyMaxFetchErrors = 3
+
+// WaitTask 轮询任务直到SUCCESS、FAILURE、CANCEL或超时, 进度变化时调用OnProgress
+func (c *MidjourneyClient) WaitTask(ctx context.Context, taskId string, options model.MidjourneyPollOptions) (res model.MidjourneyProxyFetchResponse, err error) {
+
+	logger.Infof(ctx, "Midjourney WaitTask taskId: %s start", taskId)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "Midjourney WaitTask taskId: %s totalTime: %d ms", taskId, res.TotalTime)
+	}()
+
+	if options.Interval <= 0 {
+		options.Interval = 2 * time.Second
+	}
+
+	if options.MaxInterval <= 0 {
+		options.MaxInterval = 10 * time.Second
+	}
+
+	if options.Backoff < 1 {
+		options.Backoff = 1.5
+	}
+
+	if options.Timeout <= 0 {
+		options.Timeout = 10 * time.Minute
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, options.Timeout)
+	defer cancel()
+
+	var (
+		interval    = options.Interval
+		fetchErrors = 0
+		last        = new(model.MidjourneyProgress)
+	)
+
+	// 状态或进度变化时回调, response为值拷贝, 避免与后续轮询共享
+	emit := func(status string, progress int, response model.MidjourneyProxyFetchResponse, err error) {
+
+		if err == nil && status == last.Status && progress == last.Progress {
+			return
+		}
+
+		last = &model.MidjourneyProgress{
+			TaskId:    taskId,
+			Status:    status,
+			Progress:  progress,
+			Response:  &response,
+			TotalTime: gtime.TimestampMilli() - now,
+			Error:     err,
+		}
+
+		if options.OnProgress != nil {
+			options.OnProgress(last)
+		}
+	}
+
+	// 处理轮询或回调得到的任务状态, 任务结束时返回true
+	handle := func(fetch model.MidjourneyProxyFetchResponse) (bool, error) {
+
+		res = fetch
+
+		switch res.Status {
+		case consts.MIDJOURNEY_STATUS_SUCCESS:
+			emit(res.Status, 100, res, io.EOF)
+			logger.Infof(ctx, "Midjourney WaitTask taskId: %s finished", taskId)
+			return true, nil
+		case consts.MIDJOURNEY_STATUS_FAILURE, consts.MIDJOURNEY_STATUS_CANCEL:
+			err := sdkerr.NewMidjourneyError(consts.MIDJOURNEY_CODE_FAILURE, res.FailReason, taskId)
+			emit(res.Status, ParseMidjourneyProgress(res.Progress), res, err)
+			logger.Errorf(ctx, "Midjourney WaitTask taskId: %s, error: %v", taskId, err)
+			return true, err
+		}
+
+		emit(res.Status, ParseMidjourneyProgress(res.Progress), res, nil)
+
+		return false, nil
+	}
+
+	// 设置了回调处理器时同时订阅回调, 回调与轮询先到者结束任务
+	var notifyChan <-chan *model.MidjourneyProxyFetchResponse
+	if c.notifyHandler != nil {
+		ch, unsubscribe := c.notifyHandler.Subscribe(taskId)
+		defer unsubscribe()
+		notifyChan = ch
+	}
+
+	for {
+
+		fetch, err := c.Fetch(ctx, taskId)
+		if err != nil {
+
+			if ctx.Err() != nil {
+				emit(last.Status, last.Progress, res, ctx.Err())
+				return res, ctx.Err()
+			}
+
+			if fetchErrors++; fetchErrors >= midjourneyMaxFetchErrors {
+				emit(last.Status, last.Progress, res, err)
+				return res, err
+			}
+
+		} else {
+
+			fetchErrors = 0
+
+			if done, err := handle(fetch); done {
+				return res, err
+			}
+		}
+
+		timer := time.NewTimer(interval)
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				emit(last.Status, last.Progress, res, ctx.Err())
+				logger.Errorf(ctx, "Midjourney WaitTask taskId: %s, error: %v", taskId, ctx.Err())
+				return res, ctx.Err()
+			case notify := <-notifyChan:
+				if done, err := handle(*notify); done {
+					timer.Stop()
+					return res, err
+				}
+			case <-timer.C:
+				break wait
+			}
+		}
+
+		if interval = time.Duration(float64(interval) * options.Backoff); interval > options.MaxInterval {
+			interval = options.MaxInterval
+		}
+	}
+}
+
+// PollTask 异步轮询任务, 通过通道返回进度, 最后一条的Response为最终结果, 成功时Error为io.EOF
+func (c *MidjourneyClient) PollTask(ctx context.Context, taskId string, options model.MidjourneyPollOptions) (progressChan chan *model.MidjourneyProgress, err error) {
+
+	progressChan = make(chan *model.MidjourneyProgress)
+	onProgress := options.OnProgress
+
+	options.OnProgress = func(progress *model.MidjourneyProgress) {
+
+		if onProgress != nil {
+			onProgress(progress)
+		}
+
+		select {
+		case progressChan <- progress:
+		case <-ctx.Done():
+		}
+	}
+
+	if err = grpool.AddWithRecover(ctx, func(ctx context.Context) {
+		_, _ = c.WaitTask(ctx, taskId, options)
+	}, nil); err != nil {
+		logger.Errorf(ctx, "Midjourney PollTask taskId: %s, error: %v", taskId, err)
+		return nil, err
+	}
+
+	return progressChan, nil
+}
+
+// ParseMidjourneyProgress 解析"45%"格式的进度
+func ParseMidjourneyProgress(progress string) int {
+	return gconv.Int(gstr.TrimRight(gstr.Trim(progress), "%"))
+}
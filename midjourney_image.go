@@ -0,0 +1,325 @@
+package sdk
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/grpool"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/iimeta/fastapi-sdk/logger"
+	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
+	"net/http"
+	"sync"
+)
+
+// MidjourneyImageClient 通过标准Image接口调用Midjourney, 其它接口不支持
+type MidjourneyImageClient struct {
+	client  *MidjourneyClient
+	options model.MidjourneyPollOptions
+}
+
+// 单个Imagine任务的四宫格最多放大4张
+const midjourneyMaxN = 4
+
+// NewMidjourneyImageClient key默认为midjourney-proxy的mj-api-secret, 格式为 Header|Secret 时使用指定的请求头,
+// path为接口前缀, 如部署在/api下时传/api, 各接口路径拼接在其后
+func NewMidjourneyImageClient(ctx context.Context, model, key, baseURL, path string, isSupportSystemRole *bool, proxyURL ...string) *MidjourneyImageClient {
+
+	logger.Infof(ctx, "NewMidjourneyImageClient model: %s, baseURL: %s", model, baseURL)
+
+	apiSecretHeader := "mj-api-secret"
+	if split := gstr.Split(key, "|"); len(split) == 2 {
+		apiSecretHeader = split[0]
+		key = split[1]
+	}
+
+	if path = gstr.Trim(path, "/"); path != "" {
+		logger.Infof(ctx, "NewMidjourneyImageClient model: %s, path: %s", model, path)
+		baseURL = gstr.TrimRight(baseURL, "/") + "/" + path
+	}
+
+	return &MidjourneyImageClient{
+		client: NewMidjourneyClient(ctx, baseURL, "", key, apiSecretHeader, http.MethodPost, proxyURL...),
+	}
+}
+
+// SetPollOptions 设置等待任务完成的轮询参数
+func (c *MidjourneyImageClient) SetPollOptions(options model.MidjourneyPollOptions) *MidjourneyImageClient {
+	c.options = options
+	return c
+}
+
+// SetNotifyHandler 设置回调处理器, 等待任务时同时接收notifyHook回调
+func (c *MidjourneyImageClient) SetNotifyHandler(handler *MidjourneyNotifyHandler) *MidjourneyImageClient {
+	c.client.SetNotifyHandler(handler)
+	return c
+}
+
+// Image 提交Imagine任务并等待完成, N<=1时返回四宫格图片, N>1时返回前N张放大后的图片, N不能超过4
+func (c *MidjourneyImageClient) Image(ctx context.Context, request model.ImageRequest) (res model.ImageResponse, err error) {
+
+	logger.Infof(ctx, "Image Midjourney model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "Image Midjourney model: %s totalTime: %d ms", request.Model, res.TotalTime)
+	}()
+
+	if request.N > midjourneyMaxN {
+		err = sdkerr.NewApiError(400, "invalid_n", fmt.Sprintf("Midjourney supports at most %d images per request, got %d.", midjourneyMaxN, request.N), "invalid_request_error", "n")
+		logger.Errorf(ctx, "Image Midjourney model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	imagineReq := model.MidjourneyProxyRequest{
+		Prompt:  convMidjourneyPrompt(request),
+		BotType: "MID_JOURNEY",
+	}
+
+	if gstr.ContainsI(request.Model, "niji") {
+		imagineReq.BotType = "NIJI_JOURNEY"
+	}
+
+	imagine, err := c.client.Imagine(ctx, imagineReq)
+	if err != nil {
+		logger.Errorf(ctx, "Image Midjourney model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	task, err := c.client.WaitTask(ctx, imagine.Result, c.options)
+	if err != nil {
+		logger.Errorf(ctx, "Image Midjourney model: %s, taskId: %s, error: %v", request.Model, imagine.Result, err)
+		return res, err
+	}
+
+	revisedPrompt := task.PromptEn
+	if task.Properties != nil && task.Properties.FinalPrompt != "" {
+		revisedPrompt = task.Properties.FinalPrompt
+	}
+
+	res.Created = gtime.Timestamp()
+
+	if request.N <= 1 {
+
+		res.Data = append(res.Data, model.ImageResponseDataInner{
+			URL:           task.ImageUrl,
+			RevisedPrompt: revisedPrompt,
+		})
+
+	} else {
+
+		if res.Data, err = c.upscale(ctx, task, request.N); err != nil {
+			logger.Errorf(ctx, "Image Midjourney model: %s, taskId: %s, error: %v", request.Model, task.Id, err)
+			return res, err
+		}
+
+		for i := range res.Data {
+			res.Data[i].RevisedPrompt = revisedPrompt
+		}
+	}
+
+	if request.ResponseFormat == "b64_json" {
+		for i := range res.Data {
+
+			data, err := c.download(ctx, res.Data[i].URL)
+			if err != nil {
+				logger.Errorf(ctx, "Image Midjourney model: %s, url: %s, error: %v", request.Model, res.Data[i].URL, err)
+				return res, err
+			}
+
+			res.Data[i].B64JSON = base64.StdEncoding.EncodeToString(data)
+			res.Data[i].URL = ""
+		}
+	}
+
+	logger.Infof(ctx, "Image Midjourney model: %s, taskId: %s finished", request.Model, task.Id)
+
+	return res, nil
+}
+
+// upscale 并发放大四宫格中的前n张图片
+func (c *MidjourneyImageClient) upscale(ctx context.Context, task model.MidjourneyProxyFetchResponse, n int) ([]model.ImageResponseDataInner, error) {
+
+	var customIds []string
+	for _, button := range task.Buttons {
+		if button != nil && gstr.Contains(button.CustomId, "::upsample::") && len(customIds) < n {
+			customIds = append(customIds, button.CustomId)
+		}
+	}
+
+	if len(customIds) == 0 {
+		return nil, sdkerr.NewApiError(500, "upscale_not_available", fmt.Sprintf("Task %s has no upscale buttons.", task.Id), "api_error", "")
+	}
+
+	var (
+		data = make([]model.ImageResponseDataInner, len(customIds))
+		errs = make([]error, len(customIds))
+		wg   sync.WaitGroup
+	)
+
+	for i, customId := range customIds {
+
+		wg.Add(1)
+
+		if err := grpool.AddWithRecover(ctx, func(ctx context.Context) {
+
+			defer wg.Done()
+
+			action, err := c.client.Action(ctx, model.MidjourneyProxyRequest{
+				CustomId: customId,
+				TaskId:   task.Id,
+			})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			upscaled, err := c.client.WaitTask(ctx, action.Result, c.options)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			data[i].URL = upscaled.ImageUrl
+
+		}, func(ctx context.Context, err error) {
+			errs[i] = err
+		}); err != nil {
+			wg.Done()
+			errs[i] = err
+		}
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+func (c *MidjourneyImageClient) download(ctx context.Context, url string) ([]byte, error) {
+
+	client := g.Client()
+
+	if c.client.proxyURL != "" {
+		client.SetProxy(c.client.proxyURL)
+	}
+
+	response, err := client.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := response.Close(); err != nil {
+			logger.Error(ctx, err)
+		}
+	}()
+
+	data := response.ReadAll()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, sdkerr.NewRequestError(response.StatusCode, errors.New(string(data)))
+	}
+
+	return data, nil
+}
+
+// convMidjourneyPrompt 将AspectRatio或Size转换为--ar参数, prompt中已指定时不覆盖
+func convMidjourneyPrompt(request model.ImageRequest) string {
+
+	prompt := gstr.Trim(request.Prompt)
+
+	if gstr.Contains(prompt, "--ar ") || gstr.Contains(prompt, "--aspect ") {
+		return prompt
+	}
+
+	aspectRatio := request.AspectRatio
+
+	if aspectRatio == "" && request.Size != "" {
+
+		var size []string
+		for _, sep := range []string{`×`, `x`, `X`, `*`, `:`} {
+			if size = gstr.Split(request.Size, sep); len(size) == 2 {
+				break
+			}
+		}
+
+		if len(size) == 2 {
+
+			width := gconv.Int(gstr.Trim(size[0]))
+			height := gconv.Int(gstr.Trim(size[1]))
+
+			if width > 0 && height > 0 {
+				divisor := gcd(width, height)
+				aspectRatio = fmt.Sprintf("%d:%d", width/divisor, height/divisor)
+			}
+		}
+	}
+
+	if aspectRatio == "" || aspectRatio == "1:1" {
+		return prompt
+	}
+
+	return prompt + " --ar " + aspectRatio
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func (c *MidjourneyImageClient) ChatCompletion(ctx context.Context, request model.ChatCompletionRequest) (res model.ChatCompletionResponse, err error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (c *MidjourneyImageClient) ChatCompletionStream(ctx context.Context, request model.ChatCompletionRequest) (responseChan chan *model.ChatCompletionResponse, err error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (c *MidjourneyImageClient) ImageEdit(ctx context.Context, request model.ImageEditRequest) (res model.ImageResponse, err error) {
+	return res, sdkerr.NewApiError(400, "unsupported_operation", "Midjourney does not support image edit.", "invalid_request_error", "")
+}
+
+func (c *MidjourneyImageClient) ImageVariation(ctx context.Context, request model.ImageVariationRequest) (res model.ImageResponse, err error) {
+	return res, sdkerr.NewApiError(400, "unsupported_operation", "Midjourney does not support image variation.", "invalid_request_error", "")
+}
+
+func (c *MidjourneyImageClient) Speech(ctx context.Context, request model.SpeechRequest) (res model.SpeechResponse, err error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (c *MidjourneyImageClient) Transcription(ctx context.Context, request model.AudioRequest) (res model.AudioResponse, err error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (c *MidjourneyImageClient) Translation(ctx context.Context, request model.AudioRequest) (res model.AudioResponse, err error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (c *MidjourneyImageClient) Embeddings(ctx context.Context, request model.EmbeddingRequest) (res model.EmbeddingResponse, err error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (c *MidjourneyImageClient) Moderations(ctx context.Context, request model.ModerationRequest) (res model.ModerationResponse, err error) {
+	//TODO implement me
+	panic("implement me")
+}
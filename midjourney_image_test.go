@@ -0,0 +1,34 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/iimeta/fastapi-sdk/model"
+)
+
+func TestConvMidjourneyPrompt(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		request model.ImageRequest
+		want    string
+	}{
+		{name: "no size", request: model.ImageRequest{Prompt: " a cat "}, want: "a cat"},
+		{name: "square size", request: model.ImageRequest{Prompt: "a cat", Size: "1024x1024"}, want: "a cat"},
+		{name: "landscape size", request: model.ImageRequest{Prompt: "a cat", Size: "1792x1024"}, want: "a cat --ar 7:4"},
+		{name: "portrait size with ×", request: model.ImageRequest{Prompt: "a cat", Size: "1024×1792"}, want: "a cat --ar 4:7"},
+		{name: "size with *", request: model.ImageRequest{Prompt: "a cat", Size: "1920*1080"}, want: "a cat --ar 16:9"},
+		{name: "invalid size", request: model.ImageRequest{Prompt: "a cat", Size: "large"}, want: "a cat"},
+		{name: "aspect ratio overrides size", request: model.ImageRequest{Prompt: "a cat", Size: "1792x1024", AspectRatio: "3:2"}, want: "a cat --ar 3:2"},
+		{name: "prompt already has --ar", request: model.ImageRequest{Prompt: "a cat --ar 2:3", Size: "1792x1024"}, want: "a cat --ar 2:3"},
+		{name: "prompt already has --aspect", request: model.ImageRequest{Prompt: "a cat --aspect 2:3", AspectRatio: "16:9"}, want: "a cat --aspect 2:3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convMidjourneyPrompt(tt.request); got != tt.want {
+				t.Errorf("convMidjourneyPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package sdk
+
+import (
+	"context"
+	"crypto/subtle"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/iimeta/fastapi-sdk/logger"
+	"github.com/iimeta/fastapi-sdk/model"
+	"io"
+	"net/http"
+	"sync"
+)
+
+const (
+	// 回调请求体最大长度
+	midjourneyNotifyMaxBodySize = 1024 * 1024
+	// 每个订阅者缓冲的回调数量
+	midjourneyNotifyBufferSize = 16
+)
+
+// MidjourneyNotifyHandler 接收midjourney-proxy的notifyHook回调, 按任务id分发给订阅者
+type MidjourneyNotifyHandler struct {
+	ctx         context.Context
+	secret      string
+	mutex       sync.RWMutex
+	subscribers map[string]map[chan *model.MidjourneyProxyFetchResponse]struct{}
+}
+
+// NewMidjourneyNotifyHandler secret不为空时, 回调地址需携带?secret=参数, 如notifyHook: https://host/mj/notify?secret=xxx
+func NewMidjourneyNotifyHandler(ctx context.Context, secret string) *MidjourneyNotifyHandler {
+	return &MidjourneyNotifyHandler{
+		ctx:         ctx,
+		secret:      secret,
+		subscribers: make(map[string]map[chan *model.MidjourneyProxyFetchResponse]struct{}),
+	}
+}
+
+func (h *MidjourneyNotifyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.secret != "" && subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("secret")), []byte(h.secret)) != 1 {
+		logger.Errorf(ctx, "Midjourney Notify remoteAddr: %s, error: invalid secret", r.RemoteAddr)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, midjourneyNotifyMaxBodySize))
+	if err != nil {
+		logger.Errorf(ctx, "Midjourney Notify remoteAddr: %s, error: %v", r.RemoteAddr, err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	notify := new(model.MidjourneyProxyFetchResponse)
+	if err = gjson.Unmarshal(body, notify); err != nil || notify.Id == "" {
+		logger.Errorf(ctx, "Midjourney Notify remoteAddr: %s, body: %s, error: %v", r.RemoteAddr, body, err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof(ctx, "Midjourney Notify taskId: %s, status: %s, progress: %s", notify.Id, notify.Status, notify.Progress)
+
+	h.Dispatch(notify)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Subscribe 订阅任务回调, 使用完毕后需调用unsubscribe
+func (h *MidjourneyNotifyHandler) Subscribe(taskId string) (notifyChan <-chan *model.MidjourneyProxyFetchResponse, unsubscribe func()) {
+
+	ch := make(chan *model.MidjourneyProxyFetchResponse, midjourneyNotifyBufferSize)
+
+	h.mutex.Lock()
+	if h.subscribers[taskId] == nil {
+		h.subscribers[taskId] = make(map[chan *model.MidjourneyProxyFetchResponse]struct{})
+	}
+	h.subscribers[taskId][ch] = struct{}{}
+	h.mutex.Unlock()
+
+	return ch, func() {
+
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+
+		delete(h.subscribers[taskId], ch)
+
+		if len(h.subscribers[taskId]) == 0 {
+			delete(h.subscribers, taskId)
+		}
+	}
+}
+
+// Dispatch 分发回调给订阅者, 订阅者缓冲已满时丢弃, 由轮询兜底
+func (h *MidjourneyNotifyHandler) Dispatch(notify *model.MidjourneyProxyFetchResponse) {
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for ch := range h.subscribers[notify.Id] {
+		select {
+		case ch <- notify:
+		default:
+			logger.Errorf(h.ctx, "Midjourney Notify taskId: %s, subscriber buffer is full", notify.Id)
+		}
+	}
+}
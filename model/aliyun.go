@@ -55,6 +55,8 @@ type Parameters struct {
 	// true：启用互联网搜索，模型会将搜索结果作为文本生成过程中的参考信息，但模型会基于其内部逻辑“自行判断”是否使用互联网搜索结果。
 	// false（默认）：关闭互联网搜索。
 	EnableSearch bool `json:"enable_search,omitempty"`
+	// 联网搜索的策略, 仅当enable_search为true时生效
+	SearchOptions *AliyunSearchOptions `json:"search_options,omitempty"`
 	// 用于控制流式输出模式，默认false，即后面内容会包含已经输出的内容；
 	// 设置为true，将开启增量输出模式，后面输出不会包含已经输出的内容，您需要自行拼接整体输出，参考流式输出示例代码。
 	// 该参数只能与stream输出模式配合使用。
@@ -74,6 +76,15 @@ type Parameters struct {
 	Tools any `json:"tools,omitempty"`
 }
 
+type AliyunSearchOptions struct {
+	// 是否在返回结果中展示搜索到的信息, 开启后返回search_info
+	EnableSource bool `json:"enable_source,omitempty"`
+	// 是否开启[1]或[ref_1]样式的角标标注功能, 在enable_source为true时生效
+	EnableCitation bool `json:"enable_citation,omitempty"`
+	// 角标样式, "[<number>]"或"[ref_<number>]", 默认为"[<number>]"
+	CitationFormat string `json:"citation_format,omitempty"`
+}
+
 type AliyunChatCompletionRes struct {
 	// 入参result_format=text时候的返回值
 	Output Output `json:"output"`
@@ -97,4 +108,83 @@ type Output struct {
 	FinishReason openai.FinishReason `json:"finish_reason"`
 	// 入参result_format=message时候的返回值
 	Choices []ChatCompletionChoice `json:"choices"`
+	// 联网搜索到的信息, 设置search_options.enable_source为true时返回
+	SearchInfo *SearchInfo `json:"search_info,omitempty"`
+}
+
+type AliyunImageEditReq struct {
+	// 目前支持wanx2.1-imageedit
+	Model      string                    `json:"model"`
+	Input      AliyunImageEditInput      `json:"input"`
+	Parameters AliyunImageEditParameters `json:"parameters"`
+}
+
+type AliyunImageEditInput struct {
+	// 图像编辑功能, 如description_edit(指令编辑)、description_edit_with_mask(局部重绘)、stylization_all(全局风格化)、expand(扩图)等
+	Function string `json:"function"`
+	Prompt   string `json:"prompt"`
+	// 原图的URL地址或Base64编码数据
+	BaseImageUrl string `json:"base_image_url"`
+	// 局部重绘时必填, 白色区域为需要编辑的区域
+	MaskImageUrl string `json:"mask_image_url,omitempty"`
+}
+
+type AliyunImageEditParameters struct {
+	// 生成图片的数量, 取值范围1~4
+	N int `json:"n,omitempty"`
+}
+
+type AliyunTaskRes struct {
+	RequestId string `json:"request_id"`
+	Output    struct {
+		TaskId string `json:"task_id"`
+		// PENDING、RUNNING、SUCCEEDED、FAILED、CANCELED、UNKNOWN
+		TaskStatus string `json:"task_status"`
+		Results    []struct {
+			Url     string `json:"url"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"results"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"output"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type AliyunEmbeddingReq struct {
+	// 目前支持text-embedding-v1、text-embedding-v2、text-embedding-v3
+	Model      string                    `json:"model"`
+	Input      AliyunEmbeddingInput      `json:"input"`
+	Parameters AliyunEmbeddingParameters `json:"parameters"`
+}
+
+type AliyunEmbeddingInput struct {
+	// 文本列表, text-embedding-v1、v2单次最多25条, v3单次最多10条
+	Texts []string `json:"texts"`
+}
+
+type AliyunEmbeddingParameters struct {
+	// 向量维度, 仅text-embedding-v3支持, 可选1024、768、512、256、128、64, 默认1024
+	Dimension int `json:"dimension,omitempty"`
+	// 文本类型, query或document, 默认document
+	TextType string `json:"text_type,omitempty"`
+	// 输出类型, dense、sparse或dense&sparse, 默认dense
+	OutputType string `json:"output_type,omitempty"`
+}
+
+type AliyunEmbeddingRes struct {
+	Output struct {
+		Embeddings []struct {
+			// 对应输入文本的序号
+			TextIndex int       `json:"text_index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"embeddings"`
+	} `json:"output"`
+	Usage struct {
+		TotalTokens int `json:"total_tokens"`
+	} `json:"usage"`
+	RequestId string `json:"request_id"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
 }
@@ -13,6 +13,7 @@ type AnthropicChatCompletionReq struct {
 	Tools            any                     `json:"tools,omitempty"`
 	TopK             int                     `json:"top_k,omitempty"`
 	TopP             float32                 `json:"top_p,omitempty"`
+	Thinking         *AnthropicThinking      `json:"thinking,omitempty"`
 	AnthropicVersion string                  `json:"anthropic_version,omitempty"`
 }
 
@@ -27,6 +28,7 @@ type AnthropicChatCompletionRes struct {
 	Message       AnthropicMessage   `json:"message"`
 	Index         int                `json:"index"`
 	Delta         AnthropicContent   `json:"delta"`
+	ContentBlock  ContentBlock       `json:"content_block"`
 	Usage         *AnthropicUsage    `json:"usage,omitempty"`
 	Error         *AnthropicError    `json:"error,omitempty"`
 	ResponseBytes []byte             `json:"-"`
@@ -61,17 +63,40 @@ type AnthropicContent struct {
 	Type         string       `json:"type"`
 	Text         string       `json:"text"`
 	PartialJson  string       `json:"partial_json"`
+	Id           string       `json:"id,omitempty"`
+	Name         string       `json:"name,omitempty"`
+	Input        any          `json:"input,omitempty"`
+	Thinking     string       `json:"thinking,omitempty"`
+	Signature    string       `json:"signature,omitempty"`
+	Data         string       `json:"data,omitempty"`
 	ContentBlock ContentBlock `json:"content_block,omitempty"`
 	StopReason   string       `json:"stop_reason,omitempty"`
 	StopSequence string       `json:"stop_sequence,omitempty"`
 }
 
 type ContentBlock struct {
-	Type  string `json:"type"`
-	Text  string `json:"text"`
-	Id    string `json:"id"`
-	Name  string `json:"name"`
-	Input any    `json:"input"`
+	Type      string `json:"type"`
+	Text      string `json:"text"`
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Input     any    `json:"input"`
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Data      string `json:"data,omitempty"`
+}
+
+type AnthropicThinking struct {
+	// enabled、disabled
+	Type string `json:"type"`
+	// 必须大于等于1024且小于max_tokens
+	BudgetTokens int `json:"budget_tokens,omitempty"`
+}
+
+type CacheControl struct {
+	// ephemeral
+	Type string `json:"type"`
+	// 5m、1h
+	TTL string `json:"ttl,omitempty"`
 }
 
 type AnthropicUsage struct {
@@ -97,3 +122,64 @@ type AnthropicTool struct {
 	Description string `json:"description"`
 	InputSchema any    `json:"input_schema"`
 }
+
+type AnthropicBatchReq struct {
+	Requests []AnthropicBatchRequest `json:"requests"`
+}
+
+type AnthropicBatchRequest struct {
+	// 批次内唯一, 用于匹配结果
+	CustomId string                     `json:"custom_id"`
+	Params   AnthropicChatCompletionReq `json:"params"`
+}
+
+// AnthropicBatchItem 以OpenAI格式描述的批处理请求
+type AnthropicBatchItem struct {
+	CustomId string
+	Request  ChatCompletionRequest
+}
+
+type AnthropicBatch struct {
+	Id   string `json:"id"`
+	Type string `json:"type"`
+	// in_progress、canceling、ended
+	ProcessingStatus  string                      `json:"processing_status"`
+	RequestCounts     AnthropicBatchRequestCounts `json:"request_counts"`
+	EndedAt           string                      `json:"ended_at"`
+	CreatedAt         string                      `json:"created_at"`
+	ExpiresAt         string                      `json:"expires_at"`
+	ArchivedAt        string                      `json:"archived_at"`
+	CancelInitiatedAt string                      `json:"cancel_initiated_at"`
+	ResultsUrl        string                      `json:"results_url"`
+	Error             *AnthropicError             `json:"error,omitempty"`
+}
+
+type AnthropicBatchRequestCounts struct {
+	Processing int `json:"processing"`
+	Succeeded  int `json:"succeeded"`
+	Errored    int `json:"errored"`
+	Canceled   int `json:"canceled"`
+	Expired    int `json:"expired"`
+}
+
+type AnthropicBatchList struct {
+	Data    []AnthropicBatch `json:"data"`
+	HasMore bool             `json:"has_more"`
+	FirstId string           `json:"first_id"`
+	LastId  string           `json:"last_id"`
+	Error   *AnthropicError  `json:"error,omitempty"`
+}
+
+type AnthropicBatchResult struct {
+	CustomId string                      `json:"custom_id"`
+	Result   AnthropicBatchResultContent `json:"result"`
+	Response *ChatCompletionResponse     `json:"-"`
+	Err      error                       `json:"-"`
+}
+
+type AnthropicBatchResultContent struct {
+	// succeeded、errored、canceled、expired
+	Type    string                      `json:"type"`
+	Message *AnthropicChatCompletionRes `json:"message,omitempty"`
+	Error   *AnthropicErrorResponse     `json:"error,omitempty"`
+}
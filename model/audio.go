@@ -61,3 +61,22 @@ type AudioResponse struct {
 	Text      string `json:"text"`
 	TotalTime int64  `json:"-"`
 }
+
+// LongAudioOptions 长音频分片转录参数, 仅16位PCM的WAV音频会被切分,
+// mp3、m4a、ogg等压缩格式不做切分, 超过MaxChunkSize时直接返回错误, 需调用方先转码为WAV
+type LongAudioOptions struct {
+	// 单个分片最大时长(秒), 默认600
+	MaxChunkDuration float64
+	// 单个分片最大字节数, 默认24MB
+	MaxChunkSize int
+	// 是否优先在静音处切分, 否则按固定时长切分
+	SplitOnSilence bool
+	// 静音阈值, 振幅占满幅的比例, 默认0.01
+	SilenceThreshold float64
+	// 最短静音时长(秒), 默认0.3
+	MinSilenceDuration float64
+	// 并发数, 默认4
+	Concurrency int
+	// 是否调用翻译接口
+	Translation bool
+}
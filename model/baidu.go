@@ -1,5 +1,7 @@
 package model
 
+import "github.com/iimeta/go-openai"
+
 type BaiduChatCompletionReq struct {
 	// 聊天上下文信息。说明：
 	//（1）messages成员不能为空，1个成员表示单轮对话，多个成员表示多轮对话，例如：
@@ -69,7 +71,7 @@ type BaiduChatCompletionRes struct {
 	//  content_filter：输出内容被截断、兜底、替换为**等
 	FinishReason string `json:"finish_reason"`
 	// 搜索数据，当请求参数enable_citation为true并且触发搜索时，会返回该字段
-	SearchInfo *SearchInfo
+	SearchInfo *SearchInfo `json:"search_info,omitempty"`
 	// 对话返回结果
 	Result string `json:"result"`
 	// 表示用户输入是否存在安全风险，是否关闭当前会话，清理历史会话信息
@@ -96,3 +98,195 @@ type SearchResult struct {
 	Url   string `json:"url,omitempty"`   // 搜索结果URL
 	Title string `json:"title,omitempty"` // 搜索结果标题
 }
+
+type BaiduAccessTokenRes struct {
+	// 访问凭证
+	AccessToken string `json:"access_token"`
+	// 凭证有效期(秒), 默认30天
+	ExpiresIn int64 `json:"expires_in"`
+	// 错误码, 如invalid_client
+	Error string `json:"error"`
+	// 错误描述
+	ErrorDescription string `json:"error_description"`
+}
+
+type BaiduQianfanChatCompletionReq struct {
+	// 模型名称, 如ernie-4.0-8k、ernie-4.0-turbo-8k、ernie-speed-128k
+	Model string `json:"model"`
+	// 聊天上下文信息, 兼容OpenAI格式, 支持system、user、assistant、tool角色
+	Messages []ChatCompletionMessage `json:"messages"`
+	// 是否以流式接口的形式返回数据，默认false
+	Stream bool `json:"stream,omitempty"`
+	// 流式响应的选项, include_usage为true时在最后一个chunk返回usage
+	StreamOptions any `json:"stream_options,omitempty"`
+	//（1）较高的数值会使输出更加随机，而较低的数值会使其更加集中和确定
+	//（2）默认0.8，范围 (0, 1.0]，不能为0
+	Temperature float32 `json:"temperature,omitempty"`
+	//（1）影响输出文本的多样性，取值越大，生成文本的多样性越强
+	//（2）默认0.8，取值范围 [0, 1.0]
+	TopP float32 `json:"top_p,omitempty"`
+	// 通过对已生成的token增加惩罚，减少重复生成的现象，取值范围：[1.0, 2.0]
+	PenaltyScore float32 `json:"penalty_score,omitempty"`
+	// 指定模型最大输出token数
+	MaxCompletionTokens int `json:"max_completion_tokens,omitempty"`
+	// 随机种子
+	Seed *int `json:"seed,omitempty"`
+	// 生成停止标识，当模型生成结果以stop中某个元素结尾时，停止文本生成
+	Stop []string `json:"stop,omitempty"`
+	// 正值根据迄今为止文本中的现有频率对新token进行惩罚，取值范围：[-2.0, 2.0]
+	FrequencyPenalty float32 `json:"frequency_penalty,omitempty"`
+	// 正值根据token记目前是否出现在文本中来对其进行惩罚，取值范围：[-2.0, 2.0]
+	PresencePenalty float32 `json:"presence_penalty,omitempty"`
+	// 可供模型调用的工具列表
+	Tools any `json:"tools,omitempty"`
+	// 控制模型调用工具的方式, none、auto、required或指定function
+	ToolChoice any `json:"tool_choice,omitempty"`
+	// 是否并行调用工具
+	ParallelToolCalls any `json:"parallel_tool_calls,omitempty"`
+	// 指定响应内容的格式
+	ResponseFormat any `json:"response_format,omitempty"`
+	// 表示最终用户的唯一标识符
+	User string `json:"user,omitempty"`
+	// 联网搜索配置
+	WebSearch *BaiduQianfanWebSearch `json:"web_search,omitempty"`
+}
+
+type BaiduQianfanWebSearch struct {
+	// 是否开启实时搜索
+	Enable bool `json:"enable"`
+	// 是否开启上角标返回
+	EnableCitation bool `json:"enable_citation,omitempty"`
+	// 是否返回搜索溯源信息search_results
+	EnableTrace bool `json:"enable_trace,omitempty"`
+}
+
+type BaiduQianfanChatCompletionRes struct {
+	// 本轮对话的id
+	Id string `json:"id"`
+	// 回包类型, chat.completion或chat.completion.chunk
+	Object string `json:"object"`
+	// 时间戳
+	Created int64 `json:"created"`
+	// 模型名称
+	Model string `json:"model"`
+	// 模型输出内容
+	Choices []Choice `json:"choices"`
+	// token统计信息
+	Usage *Usage `json:"usage,omitempty"`
+	// 搜索溯源信息, 开启web_search.enable_trace并且触发搜索时返回
+	SearchResults []SearchResult `json:"search_results,omitempty"`
+	// 错误信息
+	Error *BaiduQianfanError `json:"error,omitempty"`
+}
+
+type BaiduQianfanError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+type BaiduQianfanErrorResponse struct {
+	Error *BaiduQianfanError `json:"error,omitempty"`
+}
+
+type BaiduErrorRes struct {
+	ErrorCode int    `json:"error_code"`
+	ErrorMsg  string `json:"error_msg"`
+}
+
+type BaiduEmbeddingReq struct {
+	// 输入文本以获取embeddings，说明：
+	//（1）不能为空List，List的每个成员不能为空字符串
+	//（2）文本数量不超过16
+	//（3）Embedding-V1、bge-large-zh、bge-large-en每个文本token数不超过384且长度不超过1000个字符
+	Input []string `json:"input"`
+	// 表示最终用户的唯一标识符
+	UserId string `json:"user_id,omitempty"`
+}
+
+type BaiduEmbeddingRes struct {
+	// 本轮请求的id
+	Id string `json:"id"`
+	// 回包类型，固定值"embedding_list"
+	Object string `json:"object"`
+	// 时间戳
+	Created int64 `json:"created"`
+	// embedding信息，data成员数和文本数量保持一致
+	Data []struct {
+		// 固定值"embedding"
+		Object string `json:"object"`
+		// embedding 内容
+		Embedding []float32 `json:"embedding"`
+		// 序号
+		Index int `json:"index"`
+	} `json:"data"`
+	// token统计信息
+	Usage     *Usage `json:"usage,omitempty"`
+	ErrorCode int    `json:"error_code"`
+	ErrorMsg  string `json:"error_msg"`
+}
+
+type BaiduImageReq struct {
+	// 提示词，即用户希望图片包含的元素。长度限制为1024字符，建议中文或者英文单词总数量不超过150个
+	Prompt string `json:"prompt"`
+	// 反向提示词，即用户希望图片不包含的元素。长度限制为1024字符
+	NegativePrompt string `json:"negative_prompt,omitempty"`
+	// 生成图片长宽，默认值 1024x1024，取值范围如下：
+	// 适用头像：["768x768", "1024x1024", "1536x1536", "2048x2048"]
+	// 适用文章配图：["1024x768", "2048x1536"]
+	// 适用海报传单：["768x1024", "1536x2048"]
+	// 适用电脑壁纸：["1024x576", "2048x1152"]
+	// 适用海报传单：["576x1024", "1152x2048"]
+	Size string `json:"size,omitempty"`
+	// 生成图片数量，说明：默认值为1，取值范围为1-4
+	N int `json:"n,omitempty"`
+	// 迭代轮次，说明：默认值为20，取值范围为10-50
+	Steps int `json:"steps,omitempty"`
+	// 采样方式，默认值：Euler a
+	SamplerIndex string `json:"sampler_index,omitempty"`
+	// 随机种子，说明：不设置时，自动生成随机数，取值范围 [0, 4294967295]
+	Seed int `json:"seed,omitempty"`
+	// 提示词相关性，说明：默认值为5，取值范围0-30
+	CfgScale float32 `json:"cfg_scale,omitempty"`
+	// 生成风格，默认值Base
+	Style string `json:"style,omitempty"`
+	// 表示最终用户的唯一标识符
+	UserId string `json:"user_id,omitempty"`
+}
+
+type BaiduImageRes struct {
+	// 请求的ID
+	Id string `json:"id"`
+	// 回包类型，固定值"image"
+	Object string `json:"object"`
+	// 时间戳
+	Created int64 `json:"created"`
+	// 生成图片结果
+	Data []struct {
+		// 固定值"image"
+		Object string `json:"object"`
+		// 图片base64编码内容
+		B64Image string `json:"b64_image"`
+		// 序号
+		Index int `json:"index"`
+	} `json:"data"`
+	// token统计信息
+	Usage     *Usage `json:"usage,omitempty"`
+	ErrorCode int    `json:"error_code"`
+	ErrorMsg  string `json:"error_msg"`
+}
+
+type BaiduQianfanEmbeddingRes struct {
+	Object string             `json:"object"`
+	Data   []openai.Embedding `json:"data"`
+	Model  string             `json:"model"`
+	Usage  *Usage             `json:"usage,omitempty"`
+	Error  *BaiduQianfanError `json:"error,omitempty"`
+}
+
+type BaiduQianfanImageRes struct {
+	Id      string                   `json:"id"`
+	Created int64                    `json:"created"`
+	Data    []ImageResponseDataInner `json:"data"`
+	Error   *BaiduQianfanError       `json:"error,omitempty"`
+}
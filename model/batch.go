@@ -0,0 +1,65 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/iimeta/go-openai"
+)
+
+type BatchRequest struct {
+	InputFileId string `json:"input_file_id"`
+	// Endpoint e.g. /v1/chat/completions、/v1/embeddings
+	Endpoint string `json:"endpoint"`
+	// CompletionWindow 默认24h
+	CompletionWindow string         `json:"completion_window"`
+	Metadata         map[string]any `json:"metadata,omitempty"`
+}
+
+type BatchResponse struct {
+	openai.Batch
+	TotalTime int64 `json:"-"`
+}
+
+type BatchListResponse struct {
+	Object    string          `json:"object"`
+	Data      []BatchResponse `json:"data"`
+	FirstId   string          `json:"first_id"`
+	LastId    string          `json:"last_id"`
+	HasMore   bool            `json:"has_more"`
+	TotalTime int64           `json:"-"`
+}
+
+// BatchItem 批处理JSONL中的一行, ChatCompletion与Embedding二选一
+type BatchItem struct {
+	CustomId       string
+	ChatCompletion *ChatCompletionRequest
+	Embedding      *EmbeddingRequest
+}
+
+type BatchLine struct {
+	CustomId string `json:"custom_id"`
+	Method   string `json:"method"`
+	Url      string `json:"url"`
+	Body     any    `json:"body"`
+}
+
+// BatchResult 批处理输出文件或错误文件中的一行
+type BatchResult struct {
+	Id       string               `json:"id"`
+	CustomId string               `json:"custom_id"`
+	Response *BatchResultResponse `json:"response"`
+	Error    *BatchResultError    `json:"error"`
+	// 根据响应体解析出的结果
+	ChatCompletion *ChatCompletionResponse `json:"-"`
+	Embedding      *EmbeddingResponse      `json:"-"`
+}
+
+type BatchResultResponse struct {
+	StatusCode int             `json:"status_code"`
+	RequestId  string          `json:"request_id"`
+	Body       json.RawMessage `json:"body"`
+}
+
+type BatchResultError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -61,6 +61,19 @@ type ChatCompletionRequest struct {
 		Voice  string `json:"voice,omitempty"`
 		Format string `json:"format,omitempty"`
 	} `json:"audio,omitempty"`
+
+	// Google only
+	SafetySettings []SafetySetting `json:"safety_settings,omitempty"`
+
+	// Anthropic only, an explicit thinking config takes precedence over ReasoningEffort.
+	Thinking *AnthropicThinking `json:"thinking,omitempty"`
+	// Anthropic only, automatically places cache breakpoints on the system prompt,
+	// the tool list and the longest stable message prefix.
+	AutoPromptCaching bool `json:"auto_prompt_caching,omitempty"`
+
+	// EnableSearch enables the provider's built-in web search, the references are returned in Citations.
+	// Baidu, Aliyun and ZhipuAI only.
+	EnableSearch bool `json:"enable_search,omitempty"`
 }
 
 // ChatCompletionResponse represents a response structure for chat completion API.
@@ -73,6 +86,8 @@ type ChatCompletionResponse struct {
 	Usage             *Usage                    `json:"usage"`
 	SystemFingerprint string                    `json:"system_fingerprint,omitempty"`
 	PromptAnnotations []openai.PromptAnnotation `json:"prompt_annotations,omitempty"`
+	PromptFeedback    *PromptFeedback           `json:"prompt_feedback,omitempty"`
+	Citations         []Citation                `json:"citations,omitempty"`
 	ResponseBytes     []byte                    `json:"-"`
 	ConnTime          int64                     `json:"-"`
 	Duration          int64                     `json:"-"`
@@ -102,14 +117,25 @@ type ChatCompletionMessage struct {
 	ToolCallID string `json:"tool_call_id,omitempty"`
 
 	Audio *openai.Audio `json:"audio,omitempty"`
+
+	// ThinkingBlocks keeps Anthropic thinking blocks and their signatures,
+	// they must be sent back unmodified in multi-turn tool use.
+	ThinkingBlocks []ThinkingBlock `json:"thinking_blocks,omitempty"`
+
+	// Anthropic only, marks this message as a prompt cache breakpoint.
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+
+	// Citations the web search references of an assistant message.
+	Citations []Citation `json:"citations,omitempty"`
 }
 
 type ChatCompletionChoice struct {
-	Index        int                              `json:"index"`
-	Message      *ChatCompletionMessage           `json:"message,omitempty"`
-	Delta        *ChatCompletionStreamChoiceDelta `json:"delta,omitempty"`
-	LogProbs     *openai.LogProbs                 `json:"logprobs,omitempty"`
-	FinishReason openai.FinishReason              `json:"finish_reason"`
+	Index         int                              `json:"index"`
+	Message       *ChatCompletionMessage           `json:"message,omitempty"`
+	Delta         *ChatCompletionStreamChoiceDelta `json:"delta,omitempty"`
+	LogProbs      *openai.LogProbs                 `json:"logprobs,omitempty"`
+	FinishReason  openai.FinishReason              `json:"finish_reason"`
+	SafetyRatings []SafetyRating                   `json:"safety_ratings,omitempty"`
 	//ContentFilterResults *openai.ContentFilterResults            `json:"content_filter_results,omitempty"`
 }
 
@@ -133,4 +159,20 @@ type ChatCompletionStreamChoiceDelta struct {
 	ToolCalls        []openai.ToolCall    `json:"tool_calls,omitempty"`
 	Refusal          string               `json:"refusal,omitempty"`
 	Audio            *openai.Audio        `json:"audio,omitempty"`
+	ThinkingBlocks   []ThinkingBlock      `json:"thinking_blocks,omitempty"`
+	Citations        []Citation           `json:"citations,omitempty"`
+}
+
+// Citation a web search reference, Index is the marker used in the content, e.g. [1] or ^1^.
+type Citation struct {
+	Index int    `json:"index"`
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+}
+
+type ThinkingBlock struct {
+	Type      string `json:"type"`
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Data      string `json:"data,omitempty"`
 }
@@ -10,6 +10,10 @@ type EmbeddingRequest struct {
 	// Dimensions The number of dimensions the resulting output embeddings should have.
 	// Only supported in text-embedding-3 and later models.
 	Dimensions int `json:"dimensions,omitempty"`
+	// TaskType Google only, e.g. RETRIEVAL_QUERY, RETRIEVAL_DOCUMENT, SEMANTIC_SIMILARITY.
+	TaskType string `json:"task_type,omitempty"`
+	// TextType Aliyun only, query or document, text-embedding-v3 and later models.
+	TextType string `json:"text_type,omitempty"`
 }
 
 type EmbeddingResponse struct {
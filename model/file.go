@@ -0,0 +1,28 @@
+package model
+
+type FileRequest struct {
+	// FileName 上传后的文件名
+	FileName string `json:"file"`
+	// Bytes 文件内容
+	Bytes []byte `json:"-"`
+	// Purpose e.g. batch、fine-tune、assistants
+	Purpose string `json:"purpose"`
+}
+
+type FileResponse struct {
+	Id            string `json:"id"`
+	Object        string `json:"object"`
+	Bytes         int    `json:"bytes"`
+	CreatedAt     int64  `json:"created_at"`
+	FileName      string `json:"filename"`
+	Purpose       string `json:"purpose"`
+	Status        string `json:"status"`
+	StatusDetails string `json:"status_details"`
+	TotalTime     int64  `json:"-"`
+}
+
+type FileListResponse struct {
+	Object    string         `json:"object"`
+	Data      []FileResponse `json:"data"`
+	TotalTime int64          `json:"-"`
+}
@@ -3,56 +3,92 @@ package model
 import "github.com/iimeta/go-openai"
 
 type GoogleChatCompletionReq struct {
-	Contents         []Content        `json:"contents"`
-	GenerationConfig GenerationConfig `json:"generationConfig,omitempty"`
-	Tools            any              `json:"tools,omitempty"`
+	SystemInstruction *Content         `json:"systemInstruction,omitempty"`
+	Contents          []Content        `json:"contents"`
+	GenerationConfig  GenerationConfig `json:"generationConfig,omitempty"`
+	Tools             any              `json:"tools,omitempty"`
+	ToolConfig        *ToolConfig      `json:"toolConfig,omitempty"`
+	SafetySettings    []SafetySetting  `json:"safetySettings,omitempty"`
 }
 
 type GoogleChatCompletionRes struct {
-	Candidates    []Candidate    `json:"candidates"`
-	UsageMetadata *UsageMetadata `json:"usageMetadata"`
-	ModelVersion  string         `json:"modelVersion"`
-	Error         struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
-		Status  string `json:"status"`
-		Details []struct {
-			Type     string `json:"@type"`
-			Reason   string `json:"reason"`
-			Domain   string `json:"domain"`
-			Metadata struct {
-				Service string `json:"service"`
-			} `json:"metadata"`
-		} `json:"details"`
-	} `json:"error"`
-	ResponseBytes []byte `json:"-"`
-	ConnTime      int64  `json:"-"`
-	Duration      int64  `json:"-"`
-	TotalTime     int64  `json:"-"`
-	Err           error  `json:"-"`
+	Candidates     []Candidate     `json:"candidates"`
+	PromptFeedback *PromptFeedback `json:"promptFeedback,omitempty"`
+	UsageMetadata  *UsageMetadata  `json:"usageMetadata"`
+	ModelVersion   string          `json:"modelVersion"`
+	Error          GoogleError     `json:"error"`
+	ResponseBytes  []byte          `json:"-"`
+	ConnTime       int64           `json:"-"`
+	Duration       int64           `json:"-"`
+	TotalTime      int64           `json:"-"`
+	Err            error           `json:"-"`
+}
+
+type GoogleError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+	Details []struct {
+		Type     string `json:"@type"`
+		Reason   string `json:"reason"`
+		Domain   string `json:"domain"`
+		Metadata struct {
+			Service string `json:"service"`
+		} `json:"metadata"`
+	} `json:"details"`
 }
 
 type Content struct {
-	Role  string `json:"role"`
+	Role  string `json:"role,omitempty"`
 	Parts []Part `json:"parts"`
 }
 
 type Part struct {
-	Text             string      `json:"text,omitempty"`
-	InlineData       *InlineData `json:"inline_data,omitempty"`
-	FileData         *FileData   `json:"file_data,omitempty"`
-	FunctionCall     any         `json:"functionCall,omitempty"`
-	FunctionResponse any         `json:"functionResponse,omitempty"`
+	Text             string            `json:"text,omitempty"`
+	InlineData       *InlineData       `json:"inlineData,omitempty"`
+	FileData         *FileData         `json:"fileData,omitempty"`
+	FunctionCall     *FunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *FunctionResponse `json:"functionResponse,omitempty"`
 }
 
 type InlineData struct {
-	MimeType string `json:"mime_type,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
 	Data     string `json:"data,omitempty"`
 }
 
 type FileData struct {
-	FileUri  string `json:"file_uri,omitempty"`
-	MimeType string `json:"mime_type,omitempty"`
+	FileUri  string `json:"fileUri,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+type FunctionCall struct {
+	Name string `json:"name"`
+	Args any    `json:"args,omitempty"`
+}
+
+type FunctionResponse struct {
+	Name     string `json:"name"`
+	Response any    `json:"response"`
+}
+
+type GoogleTool struct {
+	FunctionDeclarations []FunctionDeclaration `json:"functionDeclarations,omitempty"`
+}
+
+type FunctionDeclaration struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+type ToolConfig struct {
+	FunctionCallingConfig *FunctionCallingConfig `json:"functionCallingConfig,omitempty"`
+}
+
+type FunctionCallingConfig struct {
+	// AUTO、ANY、NONE
+	Mode                 string   `json:"mode,omitempty"`
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
 }
 
 type Candidate struct {
@@ -65,6 +101,20 @@ type Candidate struct {
 type SafetyRating struct {
 	Category    string `json:"category"`
 	Probability string `json:"probability"`
+	Blocked     bool   `json:"blocked,omitempty"`
+}
+
+type SafetySetting struct {
+	// HARM_CATEGORY_HARASSMENT、HARM_CATEGORY_HATE_SPEECH、HARM_CATEGORY_SEXUALLY_EXPLICIT、HARM_CATEGORY_DANGEROUS_CONTENT、HARM_CATEGORY_CIVIC_INTEGRITY
+	Category string `json:"category"`
+	// BLOCK_NONE、BLOCK_ONLY_HIGH、BLOCK_MEDIUM_AND_ABOVE、BLOCK_LOW_AND_ABOVE、OFF
+	Threshold string `json:"threshold"`
+}
+
+type PromptFeedback struct {
+	// SAFETY、OTHER、BLOCKLIST、PROHIBITED_CONTENT
+	BlockReason   string         `json:"blockReason,omitempty"`
+	SafetyRatings []SafetyRating `json:"safetyRatings,omitempty"`
 }
 
 type UsageMetadata struct {
@@ -80,4 +130,64 @@ type GenerationConfig struct {
 	Temperature     float32  `json:"temperature,omitempty"`
 	TopP            float32  `json:"topP,omitempty"`
 	TopK            int      `json:"topK,omitempty"`
+	// TEXT、IMAGE, 图片生成模型需同时指定TEXT和IMAGE
+	ResponseModalities []string     `json:"responseModalities,omitempty"`
+	ImageConfig        *ImageConfig `json:"imageConfig,omitempty"`
+}
+
+type ImageConfig struct {
+	AspectRatio string `json:"aspectRatio,omitempty"`
+}
+
+type GoogleEmbeddingReq struct {
+	Model   string  `json:"model,omitempty"`
+	Content Content `json:"content"`
+	// RETRIEVAL_QUERY、RETRIEVAL_DOCUMENT、SEMANTIC_SIMILARITY、CLASSIFICATION、CLUSTERING、QUESTION_ANSWERING、FACT_VERIFICATION
+	TaskType             string `json:"taskType,omitempty"`
+	Title                string `json:"title,omitempty"`
+	OutputDimensionality int    `json:"outputDimensionality,omitempty"`
+}
+
+type GoogleBatchEmbeddingReq struct {
+	Requests []GoogleEmbeddingReq `json:"requests"`
+}
+
+type GoogleEmbeddingRes struct {
+	Embedding  *ContentEmbedding  `json:"embedding,omitempty"`
+	Embeddings []ContentEmbedding `json:"embeddings,omitempty"`
+	Error      GoogleError        `json:"error"`
+}
+
+type ContentEmbedding struct {
+	Values []float32 `json:"values"`
+}
+
+type GoogleImageReq struct {
+	Instances  []ImageInstance `json:"instances"`
+	Parameters ImageParameters `json:"parameters"`
+}
+
+type ImageInstance struct {
+	Prompt string `json:"prompt"`
+}
+
+type ImageParameters struct {
+	// 生成图片数量, 1-4
+	SampleCount int `json:"sampleCount,omitempty"`
+	// 1:1、3:4、4:3、9:16、16:9
+	AspectRatio string `json:"aspectRatio,omitempty"`
+	// dont_allow、allow_adult、allow_all
+	PersonGeneration string `json:"personGeneration,omitempty"`
+	NegativePrompt   string `json:"negativePrompt,omitempty"`
+}
+
+type GoogleImageRes struct {
+	Predictions []ImagePrediction `json:"predictions"`
+	Error       GoogleError       `json:"error"`
+}
+
+type ImagePrediction struct {
+	BytesBase64Encoded string `json:"bytesBase64Encoded"`
+	MimeType           string `json:"mimeType"`
+	Prompt             string `json:"prompt,omitempty"`
 }
@@ -1,5 +1,7 @@
 package model
 
+import "io"
+
 // ImageRequest represents the request structure for the image API.
 type ImageRequest struct {
 	Prompt         string `json:"prompt,omitempty"`
@@ -10,6 +12,8 @@ type ImageRequest struct {
 	Style          string `json:"style,omitempty"`
 	ResponseFormat string `json:"response_format,omitempty"`
 	User           string `json:"user,omitempty"`
+	// AspectRatio e.g. 1:1, 16:9, takes precedence over Size for providers that use aspect ratios.
+	AspectRatio string `json:"aspect_ratio,omitempty"`
 }
 
 type ImageResponse struct {
@@ -24,3 +28,37 @@ type ImageResponseDataInner struct {
 	B64JSON       string `json:"b64_json,omitempty"`
 	RevisedPrompt string `json:"revised_prompt,omitempty"`
 }
+
+// ImageEditRequest represents the request structure for the image edit API.
+type ImageEditRequest struct {
+	Image *ImageFile `json:"-"`
+	// Mask 完全透明的区域为需要编辑的区域, 尺寸需与Image一致, 传URL时需为厂商原生格式的遮罩
+	Mask           *ImageFile `json:"-"`
+	Prompt         string     `json:"prompt,omitempty"`
+	Model          string     `json:"model,omitempty"`
+	N              int        `json:"n,omitempty"`
+	Size           string     `json:"size,omitempty"`
+	ResponseFormat string     `json:"response_format,omitempty"`
+	User           string     `json:"user,omitempty"`
+	// Function Aliyun only, e.g. description_edit, description_edit_with_mask, stylization_all.
+	Function string `json:"function,omitempty"`
+}
+
+// ImageVariationRequest represents the request structure for the image variation API.
+type ImageVariationRequest struct {
+	Image          *ImageFile `json:"-"`
+	Model          string     `json:"model,omitempty"`
+	N              int        `json:"n,omitempty"`
+	Size           string     `json:"size,omitempty"`
+	ResponseFormat string     `json:"response_format,omitempty"`
+	User           string     `json:"user,omitempty"`
+}
+
+// ImageFile 上传的图片, Reader、Base64、URL三选一, URL仅部分厂商支持
+type ImageFile struct {
+	FileName string
+	Reader   io.Reader
+	// Base64 可带data:image/png;base64,前缀
+	Base64 string
+	URL    string
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type MidjourneyProxyRequest struct {
 	Prompt        string         `json:"prompt,omitempty"`
 	Base64        string         `json:"base64,omitempty"`
@@ -16,6 +18,7 @@ type MidjourneyProxyRequest struct {
 	AccountFilter *AccountFilter `json:"accountFilter,omitempty"`
 	MaskBase64    string         `json:"maskBase64,omitempty"`
 	Filter        *Filter        `json:"filter,omitempty"`
+	CustomId      string         `json:"customId,omitempty"`
 }
 
 type AccountFilter struct {
@@ -41,6 +44,10 @@ type MidjourneyProxyResponse struct {
 	TotalTime   int64       `json:"-"`
 }
 
+type MidjourneyProxyListByConditionRequest struct {
+	Ids []string `json:"ids"`
+}
+
 type MidjourneyResponse struct {
 	Response  []byte `json:"response,omitempty"`
 	TotalTime int64  `json:"-"`
@@ -85,3 +92,28 @@ type Button struct {
 	Style    int    `json:"style,omitempty"`
 	Type     int    `json:"type,omitempty"`
 }
+
+// MidjourneyPollOptions 任务轮询参数
+type MidjourneyPollOptions struct {
+	// 初始轮询间隔, 默认2s
+	Interval time.Duration
+	// 最大轮询间隔, 默认10s
+	MaxInterval time.Duration
+	// 轮询间隔递增倍数, 默认1.5
+	Backoff float64
+	// 超时时间, 默认10分钟
+	Timeout time.Duration
+	// 进度回调, 状态或进度变化时调用
+	OnProgress func(progress *MidjourneyProgress)
+}
+
+// MidjourneyProgress 任务进度, 最后一条的Response为最终结果, 成功时Error为io.EOF
+type MidjourneyProgress struct {
+	TaskId string
+	Status string
+	// 0-100
+	Progress  int
+	Response  *MidjourneyProxyFetchResponse
+	TotalTime int64
+	Error     error
+}
@@ -1,16 +1,200 @@
 package model
 
+import "time"
+
+// RealtimeOptions 实时会话连接参数
+type RealtimeOptions struct {
+	// 心跳间隔, 默认30s, 小于0时不发送心跳
+	PingInterval time.Duration
+	// 等待pong的超时时间, 默认10s
+	PongTimeout time.Duration
+	// 每次断线的最大重连次数, 重连成功后重新计数, 0为不重连, 重连后重放最近一次session.update
+	MaxReconnects int
+	// 重连间隔, 默认1s, 按重连次数递增
+	ReconnectInterval time.Duration
+}
+
 type RealtimeRequest struct {
 	MessageType int    `json:"message_type"`
 	Message     []byte `json:"message"`
+	// Event 不为空时序列化为文本消息发送, 忽略MessageType和Message
+	Event *RealtimeClientEvent `json:"-"`
 }
 
 type RealtimeResponse struct {
 	MessageType int    `json:"message_type"`
 	Message     []byte `json:"message"`
 	Usage       *Usage `json:"usage"`
-	ConnTime    int64  `json:"-"`
-	Duration    int64  `json:"-"`
-	TotalTime   int64  `json:"-"`
-	Error       error  `json:"-"`
+	// Event 文本消息解析后的服务端事件
+	Event     *RealtimeServerEvent `json:"-"`
+	ConnTime  int64                `json:"-"`
+	Duration  int64                `json:"-"`
+	TotalTime int64                `json:"-"`
+	Error     error                `json:"-"`
+}
+
+// RealtimeClientEvent 客户端事件, 不同type使用的字段不同
+type RealtimeClientEvent struct {
+	EventId string `json:"event_id,omitempty"`
+	Type    string `json:"type"`
+	// session.update
+	Session *RealtimeSession `json:"session,omitempty"`
+	// input_audio_buffer.append, base64编码的音频
+	Audio string `json:"audio,omitempty"`
+	// conversation.item.create
+	PreviousItemId string        `json:"previous_item_id,omitempty"`
+	Item           *RealtimeItem `json:"item,omitempty"`
+	// conversation.item.truncate、conversation.item.delete
+	ItemId       string `json:"item_id,omitempty"`
+	ContentIndex *int   `json:"content_index,omitempty"`
+	AudioEndMs   *int   `json:"audio_end_ms,omitempty"`
+	// response.create
+	Response *RealtimeResponseConfig `json:"response,omitempty"`
+	// response.cancel
+	ResponseId string `json:"response_id,omitempty"`
+}
+
+type RealtimeSession struct {
+	Id                      string                           `json:"id,omitempty"`
+	Object                  string                           `json:"object,omitempty"`
+	Model                   string                           `json:"model,omitempty"`
+	Modalities              []string                         `json:"modalities,omitempty"`
+	Instructions            string                           `json:"instructions,omitempty"`
+	Voice                   string                           `json:"voice,omitempty"`
+	InputAudioFormat        string                           `json:"input_audio_format,omitempty"`
+	OutputAudioFormat       string                           `json:"output_audio_format,omitempty"`
+	InputAudioTranscription *RealtimeInputAudioTranscription `json:"input_audio_transcription,omitempty"`
+	TurnDetection           *RealtimeTurnDetection           `json:"turn_detection,omitempty"`
+	Tools                   []RealtimeTool                   `json:"tools,omitempty"`
+	ToolChoice              any                              `json:"tool_choice,omitempty"`
+	Temperature             float32                          `json:"temperature,omitempty"`
+	// 整数或"inf"
+	MaxResponseOutputTokens any `json:"max_response_output_tokens,omitempty"`
+}
+
+type RealtimeInputAudioTranscription struct {
+	Model string `json:"model,omitempty"`
+}
+
+type RealtimeTurnDetection struct {
+	// server_vad
+	Type              string  `json:"type"`
+	Threshold         float64 `json:"threshold,omitempty"`
+	PrefixPaddingMs   int     `json:"prefix_padding_ms,omitempty"`
+	SilenceDurationMs int     `json:"silence_duration_ms,omitempty"`
+	CreateResponse    *bool   `json:"create_response,omitempty"`
+}
+
+type RealtimeTool struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+// RealtimeItem 会话项, type为message、function_call、function_call_output
+type RealtimeItem struct {
+	Id        string            `json:"id,omitempty"`
+	Object    string            `json:"object,omitempty"`
+	Type      string            `json:"type"`
+	Status    string            `json:"status,omitempty"`
+	Role      string            `json:"role,omitempty"`
+	Content   []RealtimeContent `json:"content,omitempty"`
+	CallId    string            `json:"call_id,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	Arguments string            `json:"arguments,omitempty"`
+	Output    string            `json:"output,omitempty"`
+}
+
+// RealtimeContent 内容块, type为input_text、input_audio、item_reference、text、audio
+type RealtimeContent struct {
+	Type       string `json:"type"`
+	Id         string `json:"id,omitempty"`
+	Text       string `json:"text,omitempty"`
+	Audio      string `json:"audio,omitempty"`
+	Transcript string `json:"transcript,omitempty"`
+}
+
+type RealtimeResponseConfig struct {
+	Modalities        []string       `json:"modalities,omitempty"`
+	Instructions      string         `json:"instructions,omitempty"`
+	Voice             string         `json:"voice,omitempty"`
+	OutputAudioFormat string         `json:"output_audio_format,omitempty"`
+	Tools             []RealtimeTool `json:"tools,omitempty"`
+	ToolChoice        any            `json:"tool_choice,omitempty"`
+	Temperature       float32        `json:"temperature,omitempty"`
+	// 整数或"inf"
+	MaxOutputTokens any `json:"max_output_tokens,omitempty"`
+	// auto或none
+	Conversation string            `json:"conversation,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Input        []RealtimeItem    `json:"input,omitempty"`
+}
+
+// RealtimeServerEvent 服务端事件, 不同type使用的字段不同
+type RealtimeServerEvent struct {
+	EventId        string                  `json:"event_id"`
+	Type           string                  `json:"type"`
+	Session        *RealtimeSession        `json:"session,omitempty"`
+	PreviousItemId string                  `json:"previous_item_id,omitempty"`
+	Item           *RealtimeItem           `json:"item,omitempty"`
+	ItemId         string                  `json:"item_id,omitempty"`
+	Response       *RealtimeResponseObject `json:"response,omitempty"`
+	ResponseId     string                  `json:"response_id,omitempty"`
+	OutputIndex    int                     `json:"output_index"`
+	ContentIndex   int                     `json:"content_index"`
+	Part           *RealtimeContent        `json:"part,omitempty"`
+	Delta          string                  `json:"delta,omitempty"`
+	Text           string                  `json:"text,omitempty"`
+	Transcript     string                  `json:"transcript,omitempty"`
+	CallId         string                  `json:"call_id,omitempty"`
+	Name           string                  `json:"name,omitempty"`
+	Arguments      string                  `json:"arguments,omitempty"`
+	AudioStartMs   int                     `json:"audio_start_ms,omitempty"`
+	AudioEndMs     int                     `json:"audio_end_ms,omitempty"`
+	RateLimits     []RealtimeRateLimit     `json:"rate_limits,omitempty"`
+	Error          *RealtimeError          `json:"error,omitempty"`
+}
+
+type RealtimeResponseObject struct {
+	Id            string         `json:"id"`
+	Object        string         `json:"object"`
+	Status        string         `json:"status"`
+	StatusDetails any            `json:"status_details,omitempty"`
+	Output        []RealtimeItem `json:"output,omitempty"`
+	Usage         *RealtimeUsage `json:"usage,omitempty"`
+}
+
+type RealtimeUsage struct {
+	TotalTokens       int `json:"total_tokens"`
+	InputTokens       int `json:"input_tokens"`
+	OutputTokens      int `json:"output_tokens"`
+	InputTokenDetails struct {
+		CachedTokens        int `json:"cached_tokens"`
+		TextTokens          int `json:"text_tokens"`
+		AudioTokens         int `json:"audio_tokens"`
+		CachedTokensDetails struct {
+			TextTokens  int `json:"text_tokens"`
+			AudioTokens int `json:"audio_tokens"`
+		} `json:"cached_tokens_details"`
+	} `json:"input_token_details"`
+	OutputTokenDetails struct {
+		TextTokens  int `json:"text_tokens"`
+		AudioTokens int `json:"audio_tokens"`
+	} `json:"output_token_details"`
+}
+
+type RealtimeRateLimit struct {
+	Name         string  `json:"name"`
+	Limit        int     `json:"limit"`
+	Remaining    int     `json:"remaining"`
+	ResetSeconds float64 `json:"reset_seconds"`
+}
+
+type RealtimeError struct {
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+	Param   string `json:"param,omitempty"`
+	EventId string `json:"event_id,omitempty"`
 }
@@ -0,0 +1,144 @@
+package model
+
+type ResponsesRequest struct {
+	Model string `json:"model"`
+	// Input 字符串或[]ResponsesInputItem
+	Input              any                 `json:"input"`
+	Instructions       string              `json:"instructions,omitempty"`
+	MaxOutputTokens    int                 `json:"max_output_tokens,omitempty"`
+	Temperature        float32             `json:"temperature,omitempty"`
+	TopP               float32             `json:"top_p,omitempty"`
+	Tools              any                 `json:"tools,omitempty"`
+	ToolChoice         any                 `json:"tool_choice,omitempty"`
+	ParallelToolCalls  any                 `json:"parallel_tool_calls,omitempty"`
+	PreviousResponseId string              `json:"previous_response_id,omitempty"`
+	Reasoning          *ResponsesReasoning `json:"reasoning,omitempty"`
+	Text               *ResponsesText      `json:"text,omitempty"`
+	Include            []string            `json:"include,omitempty"`
+	Store              *bool               `json:"store,omitempty"`
+	Stream             bool                `json:"stream,omitempty"`
+	Truncation         string              `json:"truncation,omitempty"`
+	User               string              `json:"user,omitempty"`
+	Metadata           map[string]string   `json:"metadata,omitempty"`
+}
+
+type ResponsesReasoning struct {
+	// low、medium、high
+	Effort string `json:"effort,omitempty"`
+	// auto、concise、detailed
+	Summary string `json:"summary,omitempty"`
+}
+
+type ResponsesText struct {
+	Format any `json:"format,omitempty"`
+}
+
+// ResponsesInputItem 输入项, type为message、function_call、function_call_output、reasoning等
+type ResponsesInputItem struct {
+	Type string `json:"type,omitempty"`
+	Id   string `json:"id,omitempty"`
+	// message
+	Role string `json:"role,omitempty"`
+	// 字符串或[]ResponsesContent
+	Content any `json:"content,omitempty"`
+	// function_call、function_call_output
+	CallId    string `json:"call_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+	Output    string `json:"output,omitempty"`
+	// reasoning
+	Summary          []ResponsesContent `json:"summary,omitempty"`
+	EncryptedContent string             `json:"encrypted_content,omitempty"`
+}
+
+// ResponsesContent 内容块, type为input_text、input_image、input_file、output_text、refusal、summary_text等
+type ResponsesContent struct {
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	ImageUrl    string `json:"image_url,omitempty"`
+	Detail      string `json:"detail,omitempty"`
+	FileId      string `json:"file_id,omitempty"`
+	FileData    string `json:"file_data,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	Refusal     string `json:"refusal,omitempty"`
+	Annotations []any  `json:"annotations,omitempty"`
+}
+
+// ResponsesOutputItem 输出项, type为message、function_call、reasoning、web_search_call等
+type ResponsesOutputItem struct {
+	Type             string             `json:"type"`
+	Id               string             `json:"id"`
+	Status           string             `json:"status,omitempty"`
+	Role             string             `json:"role,omitempty"`
+	Content          []ResponsesContent `json:"content,omitempty"`
+	CallId           string             `json:"call_id,omitempty"`
+	Name             string             `json:"name,omitempty"`
+	Arguments        string             `json:"arguments,omitempty"`
+	Summary          []ResponsesContent `json:"summary,omitempty"`
+	EncryptedContent string             `json:"encrypted_content,omitempty"`
+}
+
+type ResponsesResponse struct {
+	Id                 string                      `json:"id"`
+	Object             string                      `json:"object"`
+	CreatedAt          int64                       `json:"created_at"`
+	Model              string                      `json:"model"`
+	Status             string                      `json:"status"`
+	Output             []ResponsesOutputItem       `json:"output"`
+	Usage              *ResponsesUsage             `json:"usage,omitempty"`
+	IncompleteDetails  *ResponsesIncompleteDetails `json:"incomplete_details,omitempty"`
+	PreviousResponseId string                      `json:"previous_response_id,omitempty"`
+	Error              *ResponsesError             `json:"error,omitempty"`
+	ResponseBytes      []byte                      `json:"-"`
+	ConnTime           int64                       `json:"-"`
+	Duration           int64                       `json:"-"`
+	TotalTime          int64                       `json:"-"`
+}
+
+type ResponsesUsage struct {
+	InputTokens        int `json:"input_tokens"`
+	InputTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"input_tokens_details"`
+	OutputTokens        int `json:"output_tokens"`
+	OutputTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"output_tokens_details"`
+	TotalTokens int `json:"total_tokens"`
+}
+
+type ResponsesIncompleteDetails struct {
+	// max_output_tokens、content_filter
+	Reason string `json:"reason"`
+}
+
+type ResponsesError struct {
+	Type    string `json:"type,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+	Param   string `json:"param,omitempty"`
+}
+
+// ResponsesStreamEvent 流式事件, 不同type使用的字段不同
+type ResponsesStreamEvent struct {
+	Type           string               `json:"type"`
+	SequenceNumber int                  `json:"sequence_number"`
+	Response       *ResponsesResponse   `json:"response,omitempty"`
+	OutputIndex    int                  `json:"output_index"`
+	ContentIndex   int                  `json:"content_index"`
+	SummaryIndex   int                  `json:"summary_index"`
+	ItemId         string               `json:"item_id,omitempty"`
+	Item           *ResponsesOutputItem `json:"item,omitempty"`
+	Part           *ResponsesContent    `json:"part,omitempty"`
+	Delta          string               `json:"delta,omitempty"`
+	Text           string               `json:"text,omitempty"`
+	Arguments      string               `json:"arguments,omitempty"`
+	// error事件
+	Code          string `json:"code,omitempty"`
+	Message       string `json:"message,omitempty"`
+	ResponseBytes []byte `json:"-"`
+	ConnTime      int64  `json:"-"`
+	Duration      int64  `json:"-"`
+	TotalTime     int64  `json:"-"`
+	Error         error  `json:"-"`
+}
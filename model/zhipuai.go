@@ -53,6 +53,23 @@ type ZhipuAIChatCompletionRes struct {
 	Usage *Usage `json:"usage"`
 	// 当failed时会有错误信息
 	Error ZhipuAIError `json:"error"`
+	// 使用web_search工具时返回的网页搜索结果
+	WebSearch []ZhipuAIWebSearch `json:"web_search,omitempty"`
+}
+
+type ZhipuAIWebSearch struct {
+	// 网站图标
+	Icon string `json:"icon"`
+	// 标题
+	Title string `json:"title"`
+	// 网页链接
+	Link string `json:"link"`
+	// 网站名称
+	Media string `json:"media"`
+	// 网页摘要
+	Content string `json:"content"`
+	// 角标序号, 如[ref_1]
+	Refer string `json:"refer"`
 }
 
 type Choice struct {
@@ -79,3 +79,41 @@ func (c *Client) Transcription(ctx context.Context, request model.AudioRequest)
 
 	return res, nil
 }
+
+func (c *Client) Translation(ctx context.Context, request model.AudioRequest) (res model.AudioResponse, err error) {
+
+	logger.Infof(ctx, "Translation OpenAI model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "Translation OpenAI model: %s totalTime: %d ms", request.Model, res.TotalTime)
+	}()
+
+	response, err := c.client.CreateTranslation(ctx, openai.AudioRequest{
+		Model:       request.Model,
+		FilePath:    request.FilePath,
+		Reader:      request.Reader,
+		Prompt:      request.Prompt,
+		Temperature: request.Temperature,
+		Format:      request.Format,
+	})
+
+	if err != nil {
+		logger.Errorf(ctx, "Translation OpenAI model: %s, error: %v", request.Model, err)
+		return res, c.apiErrorHandler(err)
+	}
+
+	logger.Infof(ctx, "Translation OpenAI model: %s finished", request.Model)
+
+	res = model.AudioResponse{
+		Task:     response.Task,
+		Language: response.Language,
+		Duration: response.Duration,
+		Segments: response.Segments,
+		Words:    response.Words,
+		Text:     response.Text,
+	}
+
+	return res, nil
+}
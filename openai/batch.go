@@ -0,0 +1,325 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/iimeta/fastapi-sdk/consts"
+	"github.com/iimeta/fastapi-sdk/logger"
+	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/go-openai"
+	"io"
+	"time"
+)
+
+// 批处理结果单行最大长度
+const batchResultMaxLineSize = 32 * 1024 * 1024
+
+func (c *Client) CreateBatch(ctx context.Context, request model.BatchRequest) (res model.BatchResponse, err error) {
+
+	logger.Infof(ctx, "CreateBatch OpenAI inputFileId: %s start", request.InputFileId)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "CreateBatch OpenAI inputFileId: %s totalTime: %d ms", request.InputFileId, res.TotalTime)
+	}()
+
+	response, err := c.client.CreateBatch(ctx, openai.CreateBatchRequest{
+		InputFileID:      request.InputFileId,
+		Endpoint:         openai.BatchEndpoint(request.Endpoint),
+		CompletionWindow: request.CompletionWindow,
+		Metadata:         request.Metadata,
+	})
+	if err != nil {
+		logger.Errorf(ctx, "CreateBatch OpenAI inputFileId: %s, error: %v", request.InputFileId, err)
+		return res, c.apiErrorHandler(err)
+	}
+
+	logger.Infof(ctx, "CreateBatch OpenAI inputFileId: %s, batchId: %s finished", request.InputFileId, response.ID)
+
+	res = model.BatchResponse{
+		Batch: response.Batch,
+	}
+
+	return res, nil
+}
+
+// CreateBatchWithItems 将SDK请求构建为JSONL并上传, 再以该文件创建批处理
+func (c *Client) CreateBatchWithItems(ctx context.Context, items []model.BatchItem, completionWindow string, metadata map[string]any) (res model.BatchResponse, err error) {
+
+	jsonl, endpoint, err := BuildBatchJSONL(items)
+	if err != nil {
+		logger.Errorf(ctx, "CreateBatchWithItems OpenAI error: %v", err)
+		return res, err
+	}
+
+	file, err := c.UploadFile(ctx, model.FileRequest{
+		FileName: fmt.Sprintf("batch_%d.jsonl", gtime.TimestampMilli()),
+		Bytes:    jsonl,
+		Purpose:  string(openai.PurposeBatch),
+	})
+	if err != nil {
+		return res, err
+	}
+
+	return c.CreateBatch(ctx, model.BatchRequest{
+		InputFileId:      file.Id,
+		Endpoint:         endpoint,
+		CompletionWindow: completionWindow,
+		Metadata:         metadata,
+	})
+}
+
+func (c *Client) RetrieveBatch(ctx context.Context, batchId string) (res model.BatchResponse, err error) {
+
+	logger.Infof(ctx, "RetrieveBatch OpenAI batchId: %s start", batchId)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "RetrieveBatch OpenAI batchId: %s totalTime: %d ms", batchId, res.TotalTime)
+	}()
+
+	response, err := c.client.RetrieveBatch(ctx, batchId)
+	if err != nil {
+		logger.Errorf(ctx, "RetrieveBatch OpenAI batchId: %s, error: %v", batchId, err)
+		return res, c.apiErrorHandler(err)
+	}
+
+	logger.Infof(ctx, "RetrieveBatch OpenAI batchId: %s, status: %s finished", batchId, response.Status)
+
+	res = model.BatchResponse{
+		Batch: response.Batch,
+	}
+
+	return res, nil
+}
+
+func (c *Client) CancelBatch(ctx context.Context, batchId string) (res model.BatchResponse, err error) {
+
+	logger.Infof(ctx, "CancelBatch OpenAI batchId: %s start", batchId)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "CancelBatch OpenAI batchId: %s totalTime: %d ms", batchId, res.TotalTime)
+	}()
+
+	response, err := c.client.CancelBatch(ctx, batchId)
+	if err != nil {
+		logger.Errorf(ctx, "CancelBatch OpenAI batchId: %s, error: %v", batchId, err)
+		return res, c.apiErrorHandler(err)
+	}
+
+	logger.Infof(ctx, "CancelBatch OpenAI batchId: %s finished", batchId)
+
+	res = model.BatchResponse{
+		Batch: response.Batch,
+	}
+
+	return res, nil
+}
+
+// ListBatches after用于分页, limit为0时使用服务端默认值
+func (c *Client) ListBatches(ctx context.Context, after string, limit int) (res model.BatchListResponse, err error) {
+
+	logger.Info(ctx, "ListBatches OpenAI start")
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "ListBatches OpenAI totalTime: %d ms", res.TotalTime)
+	}()
+
+	var (
+		afterPtr *string
+		limitPtr *int
+	)
+
+	if after != "" {
+		afterPtr = &after
+	}
+
+	if limit > 0 {
+		limitPtr = &limit
+	}
+
+	response, err := c.client.ListBatch(ctx, afterPtr, limitPtr)
+	if err != nil {
+		logger.Errorf(ctx, "ListBatches OpenAI error: %v", err)
+		return res, c.apiErrorHandler(err)
+	}
+
+	logger.Info(ctx, "ListBatches OpenAI finished")
+
+	res = model.BatchListResponse{
+		Object:  response.Object,
+		FirstId: response.FirstID,
+		LastId:  response.LastID,
+		HasMore: response.HasMore,
+	}
+
+	for _, batch := range response.Data {
+		res.Data = append(res.Data, model.BatchResponse{
+			Batch: batch,
+		})
+	}
+
+	return res, nil
+}
+
+// WaitBatch 按interval轮询批处理状态, 直到进入终态或ctx被取消
+func (c *Client) WaitBatch(ctx context.Context, batchId string, interval time.Duration) (res model.BatchResponse, err error) {
+
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	for {
+
+		if res, err = c.RetrieveBatch(ctx, batchId); err != nil {
+			return res, err
+		}
+
+		switch res.Status {
+		case consts.BATCH_STATUS_COMPLETED, consts.BATCH_STATUS_FAILED, consts.BATCH_STATUS_EXPIRED, consts.BATCH_STATUS_CANCELLED:
+			return res, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// BatchResults 下载并解析批处理的输出文件与错误文件
+func (c *Client) BatchResults(ctx context.Context, batch model.BatchResponse) (results []model.BatchResult, err error) {
+
+	for _, fileId := range []*string{batch.OutputFileID, batch.ErrorFileID} {
+
+		if fileId == nil || *fileId == "" {
+			continue
+		}
+
+		content, err := c.FileContent(ctx, *fileId)
+		if err != nil {
+			return results, err
+		}
+
+		fileResults, err := DecodeBatchResults(content)
+
+		if err := content.Close(); err != nil {
+			logger.Errorf(ctx, "BatchResults OpenAI fileId: %s, content.Close error: %v", *fileId, err)
+		}
+
+		if err != nil {
+			logger.Errorf(ctx, "BatchResults OpenAI fileId: %s, error: %v", *fileId, err)
+			return results, err
+		}
+
+		results = append(results, fileResults...)
+	}
+
+	return results, nil
+}
+
+// BuildBatchJSONL 将SDK请求构建为批处理JSONL, 同一批次内的请求必须为同一endpoint
+func BuildBatchJSONL(items []model.BatchItem) (jsonl []byte, endpoint string, err error) {
+
+	buffer := new(bytes.Buffer)
+
+	for _, item := range items {
+
+		line := model.BatchLine{
+			CustomId: item.CustomId,
+			Method:   "POST",
+		}
+
+		if item.ChatCompletion != nil {
+			line.Url = string(openai.BatchEndpointChatCompletions)
+			line.Body = item.ChatCompletion
+		} else if item.Embedding != nil {
+			line.Url = string(openai.BatchEndpointEmbeddings)
+			line.Body = item.Embedding
+		} else {
+			return nil, "", errors.New(fmt.Sprintf("batch item %s has no request", item.CustomId))
+		}
+
+		if endpoint == "" {
+			endpoint = line.Url
+		} else if endpoint != line.Url {
+			return nil, "", errors.New(fmt.Sprintf("batch item %s endpoint %s does not match %s", item.CustomId, line.Url, endpoint))
+		}
+
+		data, err := gjson.Marshal(line)
+		if err != nil {
+			return nil, "", err
+		}
+
+		buffer.Write(data)
+		buffer.WriteByte('\n')
+	}
+
+	return buffer.Bytes(), endpoint, nil
+}
+
+// DecodeBatchResults 逐行解析批处理结果, 成功的响应体按类型转换为SDK响应
+func DecodeBatchResults(reader io.Reader) (results []model.BatchResult, err error) {
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), batchResultMaxLineSize)
+
+	for scanner.Scan() {
+
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		result := model.BatchResult{}
+		if err = gjson.Unmarshal(line, &result); err != nil {
+			return results, errors.New(fmt.Sprintf("line: %s, error: %v", line, err))
+		}
+
+		if result.Response != nil && result.Response.StatusCode == 200 && len(result.Response.Body) > 0 {
+
+			body := struct {
+				Object string `json:"object"`
+			}{}
+
+			if err = gjson.Unmarshal(result.Response.Body, &body); err != nil {
+				return results, err
+			}
+
+			if body.Object == consts.COMPLETION_OBJECT {
+
+				chatCompletion := new(model.ChatCompletionResponse)
+				if err = gjson.Unmarshal(result.Response.Body, chatCompletion); err != nil {
+					return results, err
+				}
+
+				result.ChatCompletion = chatCompletion
+
+			} else {
+
+				embedding := new(model.EmbeddingResponse)
+				if err = gjson.Unmarshal(result.Response.Body, embedding); err != nil {
+					return results, err
+				}
+
+				result.Embedding = embedding
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, scanner.Err()
+}
@@ -23,6 +23,10 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 		logger.Infof(ctx, "ChatCompletion OpenAI model: %s totalTime: %d ms", request.Model, res.TotalTime)
 	}()
 
+	if isResponsesModel(request.Model) {
+		return c.ResponsesChatCompletion(ctx, request)
+	}
+
 	messages := make([]openai.ChatCompletionMessage, 0)
 	for _, message := range request.Messages {
 
@@ -134,6 +138,10 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 		}
 	}()
 
+	if isResponsesModel(request.Model) {
+		return c.ResponsesChatCompletionStream(ctx, request)
+	}
+
 	if gstr.HasPrefix(request.Model, "o1-") && c.isAzure {
 		return c.O1ChatCompletionStream(ctx, request)
 	}
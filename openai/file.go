@@ -0,0 +1,138 @@
+package openai
+
+import (
+	"context"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/iimeta/fastapi-sdk/logger"
+	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/go-openai"
+	"io"
+)
+
+func (c *Client) UploadFile(ctx context.Context, request model.FileRequest) (res model.FileResponse, err error) {
+
+	logger.Infof(ctx, "UploadFile OpenAI fileName: %s start", request.FileName)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "UploadFile OpenAI fileName: %s totalTime: %d ms", request.FileName, res.TotalTime)
+	}()
+
+	response, err := c.client.CreateFileBytes(ctx, openai.FileBytesRequest{
+		Name:    request.FileName,
+		Bytes:   request.Bytes,
+		Purpose: openai.PurposeType(request.Purpose),
+	})
+	if err != nil {
+		logger.Errorf(ctx, "UploadFile OpenAI fileName: %s, error: %v", request.FileName, err)
+		return res, c.apiErrorHandler(err)
+	}
+
+	logger.Infof(ctx, "UploadFile OpenAI fileName: %s, fileId: %s finished", request.FileName, response.ID)
+
+	return convFile(response), nil
+}
+
+func (c *Client) ListFiles(ctx context.Context) (res model.FileListResponse, err error) {
+
+	logger.Info(ctx, "ListFiles OpenAI start")
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "ListFiles OpenAI totalTime: %d ms", res.TotalTime)
+	}()
+
+	response, err := c.client.ListFiles(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "ListFiles OpenAI error: %v", err)
+		return res, c.apiErrorHandler(err)
+	}
+
+	logger.Info(ctx, "ListFiles OpenAI finished")
+
+	res = model.FileListResponse{
+		Object: "list",
+	}
+
+	for _, file := range response.Files {
+		res.Data = append(res.Data, convFile(file))
+	}
+
+	return res, nil
+}
+
+func (c *Client) RetrieveFile(ctx context.Context, fileId string) (res model.FileResponse, err error) {
+
+	logger.Infof(ctx, "RetrieveFile OpenAI fileId: %s start", fileId)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "RetrieveFile OpenAI fileId: %s totalTime: %d ms", fileId, res.TotalTime)
+	}()
+
+	response, err := c.client.GetFile(ctx, fileId)
+	if err != nil {
+		logger.Errorf(ctx, "RetrieveFile OpenAI fileId: %s, error: %v", fileId, err)
+		return res, c.apiErrorHandler(err)
+	}
+
+	logger.Infof(ctx, "RetrieveFile OpenAI fileId: %s finished", fileId)
+
+	return convFile(response), nil
+}
+
+func (c *Client) DeleteFile(ctx context.Context, fileId string) (err error) {
+
+	logger.Infof(ctx, "DeleteFile OpenAI fileId: %s start", fileId)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		logger.Infof(ctx, "DeleteFile OpenAI fileId: %s totalTime: %d ms", fileId, gtime.TimestampMilli()-now)
+	}()
+
+	if err = c.client.DeleteFile(ctx, fileId); err != nil {
+		logger.Errorf(ctx, "DeleteFile OpenAI fileId: %s, error: %v", fileId, err)
+		return c.apiErrorHandler(err)
+	}
+
+	logger.Infof(ctx, "DeleteFile OpenAI fileId: %s finished", fileId)
+
+	return nil
+}
+
+// FileContent 下载文件内容, 调用方负责关闭返回的ReadCloser
+func (c *Client) FileContent(ctx context.Context, fileId string) (res io.ReadCloser, err error) {
+
+	logger.Infof(ctx, "FileContent OpenAI fileId: %s start", fileId)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		logger.Infof(ctx, "FileContent OpenAI fileId: %s totalTime: %d ms", fileId, gtime.TimestampMilli()-now)
+	}()
+
+	response, err := c.client.GetFileContent(ctx, fileId)
+	if err != nil {
+		logger.Errorf(ctx, "FileContent OpenAI fileId: %s, error: %v", fileId, err)
+		return res, c.apiErrorHandler(err)
+	}
+
+	logger.Infof(ctx, "FileContent OpenAI fileId: %s finished", fileId)
+
+	return response.ReadCloser, nil
+}
+
+func convFile(file openai.File) model.FileResponse {
+	return model.FileResponse{
+		Id:            file.ID,
+		Object:        file.Object,
+		Bytes:         file.Bytes,
+		CreatedAt:     file.CreatedAt,
+		FileName:      file.FileName,
+		Purpose:       file.Purpose,
+		Status:        file.Status,
+		StatusDetails: file.StatusDetails,
+	}
+}
@@ -1,11 +1,22 @@
 package openai
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/iimeta/fastapi-sdk/common"
 	"github.com/iimeta/fastapi-sdk/logger"
 	"github.com/iimeta/fastapi-sdk/model"
 	"github.com/iimeta/go-openai"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"time"
 )
 
 func (c *Client) Image(ctx context.Context, request model.ImageRequest) (res model.ImageResponse, err error) {
@@ -49,3 +60,163 @@ func (c *Client) Image(ctx context.Context, request model.ImageRequest) (res mod
 
 	return res, nil
 }
+
+func (c *Client) ImageEdit(ctx context.Context, request model.ImageEditRequest) (res model.ImageResponse, err error) {
+
+	logger.Infof(ctx, "ImageEdit OpenAI model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "ImageEdit OpenAI model: %s totalTime: %d ms", request.Model, gtime.TimestampMilli()-now)
+	}()
+
+	fields := map[string]string{
+		"prompt":          request.Prompt,
+		"model":           request.Model,
+		"size":            request.Size,
+		"response_format": request.ResponseFormat,
+		"user":            request.User,
+	}
+
+	if request.N > 0 {
+		fields["n"] = gconv.String(request.N)
+	}
+
+	files := map[string]*model.ImageFile{
+		"image": request.Image,
+	}
+
+	if request.Mask != nil {
+		files["mask"] = request.Mask
+	}
+
+	if res, err = c.imageMultipart(ctx, request.Model, "/images/edits", fields, files); err != nil {
+		logger.Errorf(ctx, "ImageEdit OpenAI model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	logger.Infof(ctx, "ImageEdit OpenAI model: %s finished", request.Model)
+
+	return res, nil
+}
+
+func (c *Client) ImageVariation(ctx context.Context, request model.ImageVariationRequest) (res model.ImageResponse, err error) {
+
+	logger.Infof(ctx, "ImageVariation OpenAI model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "ImageVariation OpenAI model: %s totalTime: %d ms", request.Model, gtime.TimestampMilli()-now)
+	}()
+
+	fields := map[string]string{
+		"model":           request.Model,
+		"size":            request.Size,
+		"response_format": request.ResponseFormat,
+		"user":            request.User,
+	}
+
+	if request.N > 0 {
+		fields["n"] = gconv.String(request.N)
+	}
+
+	if res, err = c.imageMultipart(ctx, request.Model, "/images/variations", fields, map[string]*model.ImageFile{"image": request.Image}); err != nil {
+		logger.Errorf(ctx, "ImageVariation OpenAI model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	logger.Infof(ctx, "ImageVariation OpenAI model: %s finished", request.Model)
+
+	return res, nil
+}
+
+// 以multipart/form-data方式上传图片, 图片支持io.Reader与base64
+func (c *Client) imageMultipart(ctx context.Context, modelName, suffix string, fields map[string]string, files map[string]*model.ImageFile) (res model.ImageResponse, err error) {
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	for name, file := range files {
+
+		fileName, data, err := common.ReadImageFile(file)
+		if err != nil {
+			return res, err
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, name, fileName))
+		header.Set("Content-Type", http.DetectContentType(data))
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return res, err
+		}
+
+		if _, err = part.Write(data); err != nil {
+			return res, err
+		}
+	}
+
+	for name, value := range fields {
+		if value != "" {
+			if err = writer.WriteField(name, value); err != nil {
+				return res, err
+			}
+		}
+	}
+
+	if err = writer.Close(); err != nil {
+		return res, err
+	}
+
+	url := c.baseURL + suffix
+	if c.isAzure {
+		// 与go-openai的默认部署名映射保持一致, 去掉模型名中的.和:
+		deployment := gstr.ReplaceByMap(modelName, map[string]string{".": "", ":": ""})
+		url = fmt.Sprintf("%s/openai/deployments/%s%s?api-version=%s", gstr.TrimRight(c.baseURL, "/"), deployment, suffix, c.apiVersion)
+	}
+
+	client := g.Client().Timeout(600 * time.Second)
+	client.SetHeaderMap(c.header)
+	client.SetHeader("Content-Type", writer.FormDataContentType())
+
+	if c.proxyURL != "" {
+		client.SetProxy(c.proxyURL)
+	}
+
+	response, err := client.Post(ctx, url, body.Bytes())
+	if err != nil {
+		return res, err
+	}
+
+	defer func() {
+		if err := response.Close(); err != nil {
+			logger.Error(ctx, err)
+		}
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return res, c.requestErrorHandler(ctx, response)
+	}
+
+	imageRes := openai.ImageResponse{}
+	if err = gjson.Unmarshal(response.ReadAll(), &imageRes); err != nil {
+		return res, err
+	}
+
+	res = model.ImageResponse{
+		Created: imageRes.Created,
+	}
+
+	for _, d := range imageRes.Data {
+		res.Data = append(res.Data, model.ImageResponseDataInner{
+			URL:           d.URL,
+			B64JSON:       d.B64JSON,
+			RevisedPrompt: d.RevisedPrompt,
+		})
+	}
+
+	return res, nil
+}
@@ -15,6 +15,10 @@ type Client struct {
 	client              *openai.Client
 	isSupportSystemRole *bool
 	isAzure             bool
+	baseURL             string
+	apiVersion          string
+	proxyURL            string
+	header              map[string]string
 }
 
 func NewClient(ctx context.Context, model, key, baseURL, path string, isSupportSystemRole *bool, proxyURL ...string) *Client {
@@ -43,10 +47,20 @@ func NewClient(ctx context.Context, model, key, baseURL, path string, isSupportS
 		}
 	}
 
-	return &Client{
+	client := &Client{
 		client:              openai.NewClientWithConfig(config),
 		isSupportSystemRole: isSupportSystemRole,
+		baseURL:             config.BaseURL,
+		header: map[string]string{
+			"Authorization": "Bearer " + key,
+		},
 	}
+
+	if len(proxyURL) > 0 {
+		client.proxyURL = proxyURL[0]
+	}
+
+	return client
 }
 
 func NewAzureClient(ctx context.Context, model, key, baseURL, path string, isSupportSystemRole *bool, proxyURL ...string) *Client {
@@ -80,11 +94,22 @@ func NewAzureClient(ctx context.Context, model, key, baseURL, path string, isSup
 		}
 	}
 
-	return &Client{
+	client := &Client{
 		client:              openai.NewClientWithConfig(config),
 		isSupportSystemRole: isSupportSystemRole,
 		isAzure:             true,
+		baseURL:             config.BaseURL,
+		apiVersion:          config.APIVersion,
+		header: map[string]string{
+			"api-key": key,
+		},
 	}
+
+	if len(proxyURL) > 0 {
+		client.proxyURL = proxyURL[0]
+	}
+
+	return client
 }
 
 func (c *Client) apiErrorHandler(err error) error {
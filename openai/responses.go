@@ -0,0 +1,624 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/os/grpool"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/iimeta/fastapi-sdk/consts"
+	"github.com/iimeta/fastapi-sdk/logger"
+	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/fastapi-sdk/sdkerr"
+	"github.com/iimeta/fastapi-sdk/util"
+	"github.com/iimeta/go-openai"
+	"io"
+)
+
+// 仅支持Responses API的模型前缀
+var responsesModels = []string{"o1-pro", "o3-pro", "o3-deep-research", "o4-mini-deep-research", "codex-mini", "computer-use-preview"}
+
+func isResponsesModel(model string) bool {
+
+	for _, prefix := range responsesModels {
+		if gstr.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Client) responsesURL() string {
+
+	if c.isAzure {
+		return fmt.Sprintf("%s/openai/responses?api-version=%s", gstr.TrimRight(c.baseURL, "/"), c.apiVersion)
+	}
+
+	return c.baseURL + "/responses"
+}
+
+func (c *Client) Responses(ctx context.Context, request model.ResponsesRequest) (res model.ResponsesResponse, err error) {
+
+	logger.Infof(ctx, "Responses OpenAI model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		res.TotalTime = gtime.TimestampMilli() - now
+		logger.Infof(ctx, "Responses OpenAI model: %s totalTime: %d ms", request.Model, res.TotalTime)
+	}()
+
+	request.Stream = false
+
+	if res.ResponseBytes, err = util.HttpPost(ctx, c.responsesURL(), c.header, request, &res, c.proxyURL); err != nil {
+		logger.Errorf(ctx, "Responses OpenAI model: %s, error: %v", request.Model, err)
+		return res, err
+	}
+
+	if res.Error != nil && res.Error.Message != "" {
+		logger.Errorf(ctx, "Responses OpenAI model: %s, res: %s", request.Model, res.ResponseBytes)
+
+		err = sdkerr.NewApiError(500, res.Error.Code, res.Error.Message, res.Error.Type, res.Error.Param)
+		logger.Errorf(ctx, "Responses OpenAI model: %s, error: %v", request.Model, err)
+
+		return res, err
+	}
+
+	logger.Infof(ctx, "Responses OpenAI model: %s finished", request.Model)
+
+	return res, nil
+}
+
+func (c *Client) ResponsesStream(ctx context.Context, request model.ResponsesRequest) (responseChan chan *model.ResponsesStreamEvent, err error) {
+
+	logger.Infof(ctx, "ResponsesStream OpenAI model: %s start", request.Model)
+
+	now := gtime.TimestampMilli()
+	defer func() {
+		if err != nil {
+			logger.Infof(ctx, "ResponsesStream OpenAI model: %s totalTime: %d ms", request.Model, gtime.TimestampMilli()-now)
+		}
+	}()
+
+	request.Stream = true
+
+	stream, err := util.SSEClient(ctx, c.responsesURL(), c.header, request, c.proxyURL, c.requestErrorHandler)
+	if err != nil {
+		logger.Errorf(ctx, "ResponsesStream OpenAI model: %s, error: %v", request.Model, err)
+		return responseChan, err
+	}
+
+	duration := gtime.TimestampMilli()
+
+	responseChan = make(chan *model.ResponsesStreamEvent)
+
+	if err = grpool.AddWithRecover(ctx, func(ctx context.Context) {
+
+		defer func() {
+			if err := stream.Close(); err != nil {
+				logger.Errorf(ctx, "ResponsesStream OpenAI model: %s, stream.Close error: %v", request.Model, err)
+			}
+
+			end := gtime.TimestampMilli()
+			logger.Infof(ctx, "ResponsesStream OpenAI model: %s connTime: %d ms, duration: %d ms, totalTime: %d ms", request.Model, duration-now, end-duration, end-now)
+		}()
+
+		for {
+
+			streamResponse, err := stream.Recv()
+			if err != nil {
+
+				if errors.Is(err, io.EOF) {
+					logger.Infof(ctx, "ResponsesStream OpenAI model: %s finished", request.Model)
+				} else if !errors.Is(err, context.Canceled) {
+					logger.Errorf(ctx, "ResponsesStream OpenAI model: %s, error: %v", request.Model, err)
+				}
+
+				end := gtime.TimestampMilli()
+				responseChan <- &model.ResponsesStreamEvent{
+					ConnTime:  duration - now,
+					Duration:  end - duration,
+					TotalTime: end - now,
+					Error:     err,
+				}
+
+				return
+			}
+
+			event := new(model.ResponsesStreamEvent)
+			if err := gjson.Unmarshal(streamResponse, &event); err != nil {
+				logger.Errorf(ctx, "ResponsesStream OpenAI model: %s, streamResponse: %s, error: %v", request.Model, streamResponse, err)
+
+				end := gtime.TimestampMilli()
+				responseChan <- &model.ResponsesStreamEvent{
+					ConnTime:  duration - now,
+					Duration:  end - duration,
+					TotalTime: end - now,
+					Error:     errors.New(fmt.Sprintf("streamResponse: %s, error: %v", streamResponse, err)),
+				}
+
+				return
+			}
+
+			event.ResponseBytes = streamResponse
+			event.ConnTime = duration - now
+
+			end := gtime.TimestampMilli()
+			event.Duration = end - duration
+			event.TotalTime = end - now
+
+			if event.Type == consts.RESPONSES_EVENT_ERROR {
+				event.Error = sdkerr.NewApiError(500, event.Code, event.Message, "api_error", "")
+				logger.Errorf(ctx, "ResponsesStream OpenAI model: %s, error: %v", request.Model, event.Error)
+				responseChan <- event
+				return
+			}
+
+			if event.Type == consts.RESPONSES_EVENT_FAILED && event.Response != nil && event.Response.Error != nil {
+				event.Error = sdkerr.NewApiError(500, event.Response.Error.Code, event.Response.Error.Message, "api_error", "")
+				logger.Errorf(ctx, "ResponsesStream OpenAI model: %s, error: %v", request.Model, event.Error)
+				responseChan <- event
+				return
+			}
+
+			// 完成事件后不再有数据, 直接结束
+			if event.Type == consts.RESPONSES_EVENT_COMPLETED || event.Type == consts.RESPONSES_EVENT_INCOMPLETE {
+				logger.Infof(ctx, "ResponsesStream OpenAI model: %s finished", request.Model)
+				event.Error = io.EOF
+				responseChan <- event
+				return
+			}
+
+			responseChan <- event
+		}
+	}, nil); err != nil {
+		logger.Errorf(ctx, "ResponsesStream OpenAI model: %s, error: %v", request.Model, err)
+		return responseChan, err
+	}
+
+	return responseChan, nil
+}
+
+// ResponsesChatCompletion 以Responses API实现ChatCompletion
+func (c *Client) ResponsesChatCompletion(ctx context.Context, request model.ChatCompletionRequest) (res model.ChatCompletionResponse, err error) {
+
+	response, err := c.Responses(ctx, convResponsesRequest(request))
+	if err != nil {
+		return res, err
+	}
+
+	res = model.ChatCompletionResponse{
+		ID:            response.Id,
+		Object:        consts.COMPLETION_OBJECT,
+		Created:       response.CreatedAt,
+		Model:         response.Model,
+		Usage:         convResponsesUsage(response.Usage),
+		ResponseBytes: response.ResponseBytes,
+		TotalTime:     response.TotalTime,
+	}
+
+	message := &model.ChatCompletionMessage{
+		Role: consts.ROLE_ASSISTANT,
+	}
+
+	var (
+		content          string
+		reasoningContent string
+	)
+
+	for _, item := range response.Output {
+		switch item.Type {
+		case consts.RESPONSES_ITEM_TYPE_MESSAGE:
+			for _, part := range item.Content {
+				if part.Type == "refusal" {
+					message.Refusal += part.Refusal
+				} else {
+					content += part.Text
+				}
+			}
+		case consts.RESPONSES_ITEM_TYPE_FUNCTION_CALL:
+			message.ToolCalls = append(message.ToolCalls, openai.ToolCall{
+				ID:   item.CallId,
+				Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name:      item.Name,
+					Arguments: item.Arguments,
+				},
+			})
+		case consts.RESPONSES_ITEM_TYPE_REASONING:
+			for _, summary := range item.Summary {
+				reasoningContent += summary.Text
+			}
+		}
+	}
+
+	message.Content = content
+
+	if reasoningContent != "" {
+		message.ReasoningContent = reasoningContent
+	}
+
+	res.Choices = append(res.Choices, model.ChatCompletionChoice{
+		Message:      message,
+		FinishReason: convResponsesFinishReason(response, len(message.ToolCalls) > 0),
+	})
+
+	return res, nil
+}
+
+// ResponsesChatCompletionStream 以Responses API实现ChatCompletionStream
+func (c *Client) ResponsesChatCompletionStream(ctx context.Context, request model.ChatCompletionRequest) (responseChan chan *model.ChatCompletionResponse, err error) {
+
+	eventChan, err := c.ResponsesStream(ctx, convResponsesRequest(request))
+	if err != nil {
+		return responseChan, err
+	}
+
+	responseChan = make(chan *model.ChatCompletionResponse)
+
+	if err = grpool.AddWithRecover(ctx, func(ctx context.Context) {
+
+		var (
+			id        string
+			created   int64
+			toolIndex = make(map[int]int)
+		)
+
+		for event := range eventChan {
+
+			response := &model.ChatCompletionResponse{
+				ID:            id,
+				Object:        consts.COMPLETION_STREAM_OBJECT,
+				Created:       created,
+				Model:         request.Model,
+				ResponseBytes: event.ResponseBytes,
+				ConnTime:      event.ConnTime,
+				Duration:      event.Duration,
+				TotalTime:     event.TotalTime,
+			}
+
+			if event.Response != nil {
+				id = event.Response.Id
+				created = event.Response.CreatedAt
+				response.ID = id
+				response.Created = created
+			}
+
+			if event.Error != nil && !errors.Is(event.Error, io.EOF) {
+				response.Error = event.Error
+				responseChan <- response
+				return
+			}
+
+			delta := &model.ChatCompletionStreamChoiceDelta{
+				Role: consts.ROLE_ASSISTANT,
+			}
+
+			switch event.Type {
+			case consts.RESPONSES_EVENT_OUTPUT_TEXT_DELTA:
+				delta.Content = event.Delta
+			case consts.RESPONSES_EVENT_REFUSAL_DELTA:
+				delta.Refusal = event.Delta
+			case consts.RESPONSES_EVENT_REASONING_SUMMARY:
+				delta.ReasoningContent = event.Delta
+			case consts.RESPONSES_EVENT_OUTPUT_ITEM_ADDED:
+
+				if event.Item == nil || event.Item.Type != consts.RESPONSES_ITEM_TYPE_FUNCTION_CALL {
+					continue
+				}
+
+				index := len(toolIndex)
+				toolIndex[event.OutputIndex] = index
+
+				delta.ToolCalls = []openai.ToolCall{{
+					Index: &index,
+					ID:    event.Item.CallId,
+					Type:  openai.ToolTypeFunction,
+					Function: openai.FunctionCall{
+						Name: event.Item.Name,
+					},
+				}}
+
+			case consts.RESPONSES_EVENT_FUNCTION_CALL_ARGS:
+
+				index := toolIndex[event.OutputIndex]
+
+				delta.ToolCalls = []openai.ToolCall{{
+					Index: &index,
+					Function: openai.FunctionCall{
+						Arguments: event.Delta,
+					},
+				}}
+
+			case consts.RESPONSES_EVENT_COMPLETED, consts.RESPONSES_EVENT_INCOMPLETE:
+
+				if event.Response == nil {
+					event.Response = new(model.ResponsesResponse)
+				}
+
+				response.Choices = append(response.Choices, model.ChatCompletionChoice{
+					Delta:        new(model.ChatCompletionStreamChoiceDelta),
+					FinishReason: convResponsesFinishReason(*event.Response, len(toolIndex) > 0),
+				})
+
+				response.Usage = convResponsesUsage(event.Response.Usage)
+				response.Error = io.EOF
+				responseChan <- response
+
+				return
+
+			default:
+				if errors.Is(event.Error, io.EOF) {
+					response.Error = io.EOF
+					responseChan <- response
+					return
+				}
+				continue
+			}
+
+			response.Choices = append(response.Choices, model.ChatCompletionChoice{
+				Delta: delta,
+			})
+
+			responseChan <- response
+		}
+	}, nil); err != nil {
+		logger.Errorf(ctx, "ResponsesChatCompletionStream OpenAI model: %s, error: %v", request.Model, err)
+		return responseChan, err
+	}
+
+	return responseChan, nil
+}
+
+func (c *Client) requestErrorHandler(ctx context.Context, response *gclient.Response) error {
+
+	bytes := response.ReadAll()
+
+	errRes := struct {
+		Error *model.ResponsesError `json:"error"`
+	}{}
+
+	if err := gjson.Unmarshal(bytes, &errRes); err != nil || errRes.Error == nil {
+		return sdkerr.NewRequestError(response.StatusCode, errors.New(fmt.Sprintf("response: %s, error: %v", bytes, err)))
+	}
+
+	return sdkerr.NewApiError(response.StatusCode, errRes.Error.Code, errRes.Error.Message, errRes.Error.Type, errRes.Error.Param)
+}
+
+// 转换为Responses API的请求参数
+func convResponsesRequest(request model.ChatCompletionRequest) model.ResponsesRequest {
+
+	// Responses API默认store为true, 需显式传false避免对话请求被存储
+	responsesReq := model.ResponsesRequest{
+		Model:             request.Model,
+		MaxOutputTokens:   request.MaxCompletionTokens,
+		Temperature:       request.Temperature,
+		TopP:              request.TopP,
+		Tools:             convResponsesTools(request.Tools),
+		ToolChoice:        convResponsesToolChoice(request.ToolChoice),
+		ParallelToolCalls: request.ParallelToolCalls,
+		Store:             &request.Store,
+		Stream:            request.Stream,
+		User:              request.User,
+		Metadata:          request.Metadata,
+	}
+
+	if responsesReq.MaxOutputTokens == 0 {
+		responsesReq.MaxOutputTokens = request.MaxTokens
+	}
+
+	if request.ReasoningEffort != "" {
+		responsesReq.Reasoning = &model.ResponsesReasoning{
+			Effort:  request.ReasoningEffort,
+			Summary: "auto",
+		}
+	}
+
+	if request.ResponseFormat != nil {
+
+		format := map[string]interface{}{
+			"type": request.ResponseFormat.Type,
+		}
+
+		// Responses API中json_schema的字段与type同级
+		if request.ResponseFormat.JSONSchema != nil {
+
+			jsonSchema := make(map[string]interface{})
+			if err := gjson.Unmarshal(gjson.MustEncode(request.ResponseFormat.JSONSchema), &jsonSchema); err == nil {
+				for key, value := range jsonSchema {
+					format[key] = value
+				}
+			}
+		}
+
+		responsesReq.Text = &model.ResponsesText{
+			Format: format,
+		}
+	}
+
+	items := make([]model.ResponsesInputItem, 0)
+
+	for _, message := range request.Messages {
+
+		if message.ToolCallID != "" {
+			items = append(items, model.ResponsesInputItem{
+				Type:   consts.RESPONSES_ITEM_TYPE_FUNCTION_CALL_OUTPUT,
+				CallId: message.ToolCallID,
+				Output: gconv.String(message.Content),
+			})
+			continue
+		}
+
+		if content := convResponsesContent(message.Role, message.Content); content != nil {
+			items = append(items, model.ResponsesInputItem{
+				Type:    consts.RESPONSES_ITEM_TYPE_MESSAGE,
+				Role:    message.Role,
+				Content: content,
+			})
+		}
+
+		for _, toolCall := range message.ToolCalls {
+			items = append(items, model.ResponsesInputItem{
+				Type:      consts.RESPONSES_ITEM_TYPE_FUNCTION_CALL,
+				CallId:    toolCall.ID,
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+			})
+		}
+	}
+
+	responsesReq.Input = items
+
+	return responsesReq
+}
+
+// 转换消息内容, 字符串原样返回, 多模态内容转换为input_text、input_image等内容块
+func convResponsesContent(role string, content any) any {
+
+	contents, ok := content.([]interface{})
+	if !ok {
+
+		if text := gconv.String(content); text != "" {
+			return text
+		}
+
+		return nil
+	}
+
+	textType := "input_text"
+	if role == consts.ROLE_ASSISTANT {
+		textType = "output_text"
+	}
+
+	parts := make([]model.ResponsesContent, 0)
+
+	for _, value := range contents {
+
+		part, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		switch part["type"] {
+		case "text":
+			parts = append(parts, model.ResponsesContent{
+				Type: textType,
+				Text: gconv.String(part["text"]),
+			})
+		case "image_url":
+
+			imageUrl := gconv.Map(part["image_url"])
+
+			parts = append(parts, model.ResponsesContent{
+				Type:     "input_image",
+				ImageUrl: gconv.String(imageUrl["url"]),
+				Detail:   gconv.String(imageUrl["detail"]),
+			})
+
+		case "file":
+
+			file := gconv.Map(part["file"])
+
+			parts = append(parts, model.ResponsesContent{
+				Type:     "input_file",
+				FileId:   gconv.String(file["file_id"]),
+				FileData: gconv.String(file["file_data"]),
+				Filename: gconv.String(file["filename"]),
+			})
+		}
+	}
+
+	if len(parts) == 0 {
+		return nil
+	}
+
+	return parts
+}
+
+// 转换为Responses API的工具格式, function的字段与type同级, 内置工具原样透传
+func convResponsesTools(tools any) any {
+
+	if tools == nil {
+		return nil
+	}
+
+	values := make([]interface{}, 0)
+	if err := gjson.Unmarshal(gjson.MustEncode(tools), &values); err != nil {
+		return tools
+	}
+
+	for i, value := range values {
+
+		tool, ok := value.(map[string]interface{})
+		if !ok || tool["type"] != "function" {
+			continue
+		}
+
+		if function, ok := tool["function"].(map[string]interface{}); ok {
+
+			function["type"] = "function"
+
+			values[i] = function
+		}
+	}
+
+	return values
+}
+
+func convResponsesToolChoice(toolChoice any) any {
+
+	choice, ok := toolChoice.(map[string]interface{})
+	if !ok {
+		return toolChoice
+	}
+
+	if function, ok := choice["function"].(map[string]interface{}); ok {
+		return map[string]interface{}{
+			"type": "function",
+			"name": function["name"],
+		}
+	}
+
+	return toolChoice
+}
+
+func convResponsesUsage(usage *model.ResponsesUsage) *model.Usage {
+
+	if usage == nil {
+		return nil
+	}
+
+	return &model.Usage{
+		PromptTokens:     usage.InputTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      usage.TotalTokens,
+		PromptTokensDetails: &openai.PromptTokensDetails{
+			CachedTokens: usage.InputTokensDetails.CachedTokens,
+		},
+		CompletionTokensDetails: &openai.CompletionTokensDetails{
+			ReasoningTokens: usage.OutputTokensDetails.ReasoningTokens,
+		},
+	}
+}
+
+func convResponsesFinishReason(response model.ResponsesResponse, hasToolCalls bool) openai.FinishReason {
+
+	if response.IncompleteDetails != nil {
+		switch response.IncompleteDetails.Reason {
+		case "max_output_tokens":
+			return openai.FinishReasonLength
+		case "content_filter":
+			return openai.FinishReasonContentFilter
+		}
+	}
+
+	if hasToolCalls {
+		return openai.FinishReasonToolCalls
+	}
+
+	return openai.FinishReasonStop
+}
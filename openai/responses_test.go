@@ -0,0 +1,95 @@
+package openai
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/iimeta/fastapi-sdk/consts"
+	"github.com/iimeta/fastapi-sdk/model"
+	"github.com/iimeta/go-openai"
+)
+
+func TestConvResponsesRequest(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		request model.ChatCompletionRequest
+		want    map[string]string
+		absent  []string
+	}{
+		{
+			name: "default request sends store false",
+			request: model.ChatCompletionRequest{
+				Model:    "o3-pro",
+				Messages: []model.ChatCompletionMessage{{Role: consts.ROLE_USER, Content: "hi"}},
+			},
+			want: map[string]string{
+				"store":           "false",
+				"input.0.type":    consts.RESPONSES_ITEM_TYPE_MESSAGE,
+				"input.0.role":    consts.ROLE_USER,
+				"input.0.content": "hi",
+			},
+			absent: []string{"max_output_tokens", "reasoning", "text"},
+		},
+		{
+			name: "store, max tokens and reasoning effort",
+			request: model.ChatCompletionRequest{
+				Model:           "o1-pro",
+				Messages:        []model.ChatCompletionMessage{{Role: consts.ROLE_USER, Content: "hi"}},
+				Store:           true,
+				MaxTokens:       1024,
+				ReasoningEffort: "high",
+			},
+			want: map[string]string{
+				"store":             "true",
+				"max_output_tokens": "1024",
+				"reasoning.effort":  "high",
+				"reasoning.summary": "auto",
+			},
+		},
+		{
+			name: "tool calls and tool results become function call items",
+			request: model.ChatCompletionRequest{
+				Model: "codex-mini-latest",
+				Messages: []model.ChatCompletionMessage{
+					{Role: consts.ROLE_USER, Content: "weather?"},
+					{Role: consts.ROLE_ASSISTANT, ToolCalls: []openai.ToolCall{{
+						ID:       "call_1",
+						Type:     openai.ToolTypeFunction,
+						Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Beijing"}`},
+					}}},
+					{Role: consts.ROLE_TOOL, ToolCallID: "call_1", Content: "sunny"},
+				},
+			},
+			want: map[string]string{
+				"input.1.type":      consts.RESPONSES_ITEM_TYPE_FUNCTION_CALL,
+				"input.1.call_id":   "call_1",
+				"input.1.name":      "get_weather",
+				"input.1.arguments": `{"city":"Beijing"}`,
+				"input.2.type":      consts.RESPONSES_ITEM_TYPE_FUNCTION_CALL_OUTPUT,
+				"input.2.call_id":   "call_1",
+				"input.2.output":    "sunny",
+			},
+			absent: []string{"input.3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			encoded := gjson.New(gjson.MustEncode(convResponsesRequest(tt.request)))
+
+			for path, want := range tt.want {
+				if got := encoded.Get(path); got.IsNil() || got.String() != want {
+					t.Errorf("%s = %v, want %s", path, got, want)
+				}
+			}
+
+			for _, path := range tt.absent {
+				if got := encoded.Get(path); !got.IsNil() {
+					t.Errorf("%s = %v, want absent", path, got)
+				}
+			}
+		})
+	}
+}
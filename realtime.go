@@ -1,25 +1,36 @@
 package sdk
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"github.com/gogf/gf/v2/encoding/gjson"
 	"github.com/gogf/gf/v2/os/grpool"
 	"github.com/gogf/gf/v2/os/gtime"
 	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gorilla/websocket"
+	"github.com/iimeta/fastapi-sdk/consts"
 	"github.com/iimeta/fastapi-sdk/logger"
 	"github.com/iimeta/fastapi-sdk/model"
 	"github.com/iimeta/fastapi-sdk/util"
+	"github.com/iimeta/go-openai"
 	"io"
 	"net/http"
+	"sync"
+	"time"
 )
 
 type RealtimeClient struct {
-	model    string
-	key      string
-	baseURL  string
-	path     string
-	proxyURL string
+	model      string
+	key        string
+	baseURL    string
+	path       string
+	proxyURL   string
+	isAzure    bool
+	apiVersion string
+	options    model.RealtimeOptions
 }
 
 func NewRealtimeClient(ctx context.Context, model, key, baseURL, path string, proxyURL ...string) *RealtimeClient {
@@ -51,6 +62,47 @@ func NewRealtimeClient(ctx context.Context, model, key, baseURL, path string, pr
 	return realtimeClient
 }
 
+func NewAzureRealtimeClient(ctx context.Context, model, key, baseURL, path string, proxyURL ...string) *RealtimeClient {
+
+	logger.Infof(ctx, "NewAzureRealtimeClient OpenAI model: %s, baseURL: %s, key: %s", model, baseURL, key)
+
+	realtimeClient := &RealtimeClient{
+		model:      model,
+		key:        key,
+		baseURL:    gstr.TrimRight(baseURL, "/"),
+		path:       "/openai/realtime",
+		isAzure:    true,
+		apiVersion: "2024-10-01-preview",
+	}
+
+	if path != "" {
+		logger.Infof(ctx, "NewAzureRealtimeClient OpenAI model: %s, path: %s", model, path)
+
+		split := gstr.Split(path, "?api-version=")
+
+		if split[0] != "" {
+			realtimeClient.path = split[0]
+		}
+
+		if len(split) > 1 && split[1] != "" {
+			realtimeClient.apiVersion = split[1]
+		}
+	}
+
+	if len(proxyURL) > 0 && proxyURL[0] != "" {
+		logger.Infof(ctx, "NewAzureRealtimeClient OpenAI model: %s, proxyURL: %s", model, proxyURL[0])
+		realtimeClient.proxyURL = proxyURL[0]
+	}
+
+	return realtimeClient
+}
+
+// SetOptions 设置心跳与重连参数
+func (c *RealtimeClient) SetOptions(options model.RealtimeOptions) *RealtimeClient {
+	c.options = options
+	return c
+}
+
 func (c *RealtimeClient) Realtime(ctx context.Context, requestChan chan *model.RealtimeRequest) (responseChan chan *model.RealtimeResponse, err error) {
 
 	now := gtime.TimestampMilli()
@@ -60,13 +112,11 @@ func (c *RealtimeClient) Realtime(ctx context.Context, requestChan chan *model.R
 
 	logger.Infof(ctx, "Realtime OpenAI model: %s start", c.model)
 
-	requestHeader := http.Header{
-		"Authorization": {"Bearer " + c.key},
-		"OpenAI-Beta":   {"realtime=v1"},
-	}
+	sessionCtx, cancel := context.WithCancel(ctx)
 
-	conn, err := util.WebSocketClient(ctx, c.getWebSocketUrl(ctx), requestHeader, 0, nil, c.proxyURL)
+	conn, err := c.connect(sessionCtx)
 	if err != nil {
+		cancel()
 		logger.Errorf(ctx, "Realtime OpenAI model: %s, error: %v", c.model, err)
 		return
 	}
@@ -74,8 +124,180 @@ func (c *RealtimeClient) Realtime(ctx context.Context, requestChan chan *model.R
 	duration := gtime.TimestampMilli()
 	responseChan = make(chan *model.RealtimeResponse)
 
+	var (
+		// 保护conn、generation、reconnects、reconnecting、closing和sessionUpdate
+		mutex      sync.Mutex
+		generation int
+		reconnects int
+		// 重连进行中时非nil, 重连结束后关闭
+		reconnecting chan struct{}
+		closing      bool
+		// 最近一次session.update, 重连后重放
+		sessionUpdate []byte
+		closeOnce     sync.Once
+		readerDone    = make(chan struct{})
+	)
+
+	getConn := func() (*util.WebSocketConn, int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return conn, generation
+	}
+
+	isClosing := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return closing
+	}
+
+	send := func(response *model.RealtimeResponse) {
+		select {
+		case responseChan <- response:
+		case <-ctx.Done():
+		}
+	}
+
+	errorResponse := func(err error) *model.RealtimeResponse {
+		end := gtime.TimestampMilli()
+		return &model.RealtimeResponse{
+			ConnTime:  duration - now,
+			Duration:  end - duration,
+			TotalTime: end - now,
+			Error:     err,
+		}
+	}
+
+	// 结束会话, 关闭连接并停止读写协程, response为nil时表示正常关闭
+	shutdown := func(response *model.RealtimeResponse) {
+		closeOnce.Do(func() {
+
+			cancel()
+
+			mutex.Lock()
+			closing = true
+			current := conn
+			mutex.Unlock()
+
+			if err := current.Close(); err != nil {
+				logger.Errorf(ctx, "Realtime OpenAI model: %s, conn.Close error: %v", c.model, err)
+			}
+
+			send(response)
+		})
+	}
+
+	// 断线重连, 退避与建连均在锁外进行, 仅在检查与替换conn、generation时加锁,
+	// 同一时刻只有一个协程执行重连, 其它协程等待其完成后直接使用新连接
+	reconnect := func(failedGeneration int) (*util.WebSocketConn, error) {
+
+		mutex.Lock()
+
+		for reconnecting != nil && generation == failedGeneration && !closing {
+
+			done := reconnecting
+			mutex.Unlock()
+
+			select {
+			case <-done:
+			case <-sessionCtx.Done():
+				return nil, sessionCtx.Err()
+			}
+
+			mutex.Lock()
+		}
+
+		if closing {
+			mutex.Unlock()
+			return nil, errors.New("realtime session is closing")
+		}
+
+		if generation != failedGeneration {
+			current := conn
+			mutex.Unlock()
+			return current, nil
+		}
+
+		done := make(chan struct{})
+		reconnecting = done
+		failedConn := conn
+		mutex.Unlock()
+
+		defer func() {
+			mutex.Lock()
+			reconnecting = nil
+			mutex.Unlock()
+			close(done)
+		}()
+
+		if err := failedConn.Close(); err != nil {
+			logger.Errorf(ctx, "Realtime OpenAI model: %s, conn.Close error: %v", c.model, err)
+		}
+
+		interval := c.options.ReconnectInterval
+		if interval <= 0 {
+			interval = time.Second
+		}
+
+		for {
+
+			mutex.Lock()
+			if reconnects >= c.options.MaxReconnects {
+				mutex.Unlock()
+				break
+			}
+			reconnects++
+			attempt := reconnects
+			mutex.Unlock()
+
+			select {
+			case <-sessionCtx.Done():
+				return nil, sessionCtx.Err()
+			case <-time.After(interval * time.Duration(attempt)):
+			}
+
+			logger.Infof(ctx, "Realtime OpenAI model: %s, reconnect: %d", c.model, attempt)
+
+			newConn, err := c.connect(sessionCtx)
+			if err != nil {
+				logger.Errorf(ctx, "Realtime OpenAI model: %s, reconnect: %d, error: %v", c.model, attempt, err)
+				continue
+			}
+
+			mutex.Lock()
+			update := sessionUpdate
+			mutex.Unlock()
+
+			if update != nil {
+				if err = newConn.WriteMessage(ctx, websocket.TextMessage, update); err != nil {
+					logger.Errorf(ctx, "Realtime OpenAI model: %s, reconnect: %d, session.update error: %v", c.model, attempt, err)
+					_ = newConn.Close()
+					continue
+				}
+			}
+
+			mutex.Lock()
+
+			// 重连期间会话已关闭, 丢弃新连接
+			if closing {
+				mutex.Unlock()
+				_ = newConn.Close()
+				return nil, errors.New("realtime session is closing")
+			}
+
+			conn = newConn
+			generation++
+			// 重连成功后重新计数, MaxReconnects限制的是单次断线的重试次数
+			reconnects = 0
+			mutex.Unlock()
+
+			return newConn, nil
+		}
+
+		return nil, errors.New(fmt.Sprintf("realtime reconnect failed after %d attempts", c.options.MaxReconnects))
+	}
+
 	// WriteMessage
-	if err := grpool.AddWithRecover(ctx, func(ctx context.Context) {
+	if err = grpool.AddWithRecover(ctx, func(ctx context.Context) {
 
 		defer func() {
 			logger.Infof(ctx, "Realtime OpenAI WriteMessage model: %s totalTime: %d ms", c.model, gtime.TimestampMilli()-now)
@@ -83,27 +305,88 @@ func (c *RealtimeClient) Realtime(ctx context.Context, requestChan chan *model.R
 
 		for {
 
-			request := <-requestChan
+			var request *model.RealtimeRequest
+
+			select {
+			case <-sessionCtx.Done():
+				shutdown(errorResponse(ctx.Err()))
+				return
+			case request = <-requestChan:
+			}
 
 			if request == nil || request.MessageType == -1 {
 
-				if err := conn.Close(); err != nil {
-					logger.Errorf(ctx, "Realtime OpenAI WriteMessage model: %s, conn.Close error: %v", c.model, err)
+				mutex.Lock()
+				closing = true
+				current := conn
+				mutex.Unlock()
+
+				// 发送关闭帧并等待对端确认, 超时后直接关闭
+				if err := current.WriteClose(ctx); err == nil {
+					select {
+					case <-readerDone:
+					case <-time.After(3 * time.Second):
+					}
 				}
 
-				responseChan <- nil
+				shutdown(nil)
 
 				return
 			}
 
-			if err := conn.WriteMessage(ctx, request.MessageType, request.Message); err != nil {
+			if request.Event != nil {
+
+				message, err := gjson.Marshal(request.Event)
+				if err != nil {
+					logger.Errorf(ctx, "Realtime OpenAI WriteMessage model: %s, event: %s, error: %v", c.model, request.Event.Type, err)
+					continue
+				}
+
+				request = &model.RealtimeRequest{
+					MessageType: websocket.TextMessage,
+					Message:     message,
+				}
+			}
+
+			if request.MessageType == websocket.TextMessage && bytes.Contains(request.Message, []byte(consts.REALTIME_EVENT_SESSION_UPDATE)) &&
+				gjson.New(request.Message).Get("type").String() == consts.REALTIME_EVENT_SESSION_UPDATE {
+				mutex.Lock()
+				sessionUpdate = request.Message
+				mutex.Unlock()
+			}
+
+			for {
+
+				current, currentGeneration := getConn()
+
+				err := current.WriteMessage(ctx, request.MessageType, request.Message)
+				if err == nil {
+					break
+				}
+
+				if isClosing() {
+					return
+				}
+
 				logger.Errorf(ctx, "Realtime OpenAI WriteMessage model: %s, error: %v", c.model, err)
+
+				if c.options.MaxReconnects > 0 {
+					if _, err = reconnect(currentGeneration); err == nil {
+						continue
+					}
+					logger.Errorf(ctx, "Realtime OpenAI WriteMessage model: %s, error: %v", c.model, err)
+				}
+
+				shutdown(errorResponse(err))
+
 				return
 			}
 		}
 
 	}, nil); err != nil {
 		logger.Errorf(ctx, "Realtime OpenAI WriteMessage model: %s, error: %v", c.model, err)
+		cancel()
+		_ = conn.Close()
 		return nil, err
 	}
 
@@ -111,31 +394,44 @@ func (c *RealtimeClient) Realtime(ctx context.Context, requestChan chan *model.R
 	if err = grpool.AddWithRecover(ctx, func(ctx context.Context) {
 
 		defer func() {
+			close(readerDone)
 			end := gtime.TimestampMilli()
 			logger.Infof(ctx, "Realtime OpenAI ReadMessage model: %s connTime: %d ms, duration: %d ms, totalTime: %d ms", c.model, duration-now, end-duration, end-now)
 		}()
 
 		for {
 
-			messageType, message, err := conn.ReadMessage(ctx)
-			if err != nil && !errors.Is(err, io.EOF) {
+			current, currentGeneration := getConn()
 
-				if !errors.Is(err, context.Canceled) {
-					logger.Errorf(ctx, "Realtime OpenAI ReadMessage model: %s, error: %v", c.model, err)
+			messageType, message, err := current.ReadMessage(ctx)
+			if err != nil {
+
+				if isClosing() {
+					return
 				}
 
-				end := gtime.TimestampMilli()
-				responseChan <- &model.RealtimeResponse{
-					ConnTime:  duration - now,
-					Duration:  end - duration,
-					TotalTime: end - now,
-					Error:     err,
+				if sessionCtx.Err() != nil {
+					shutdown(errorResponse(ctx.Err()))
+					return
 				}
 
-				return
-			}
+				// 服务端正常关闭
+				if errors.Is(err, io.EOF) {
+					shutdown(nil)
+					return
+				}
+
+				logger.Errorf(ctx, "Realtime OpenAI ReadMessage model: %s, error: %v", c.model, err)
+
+				if c.options.MaxReconnects > 0 {
+					if _, err = reconnect(currentGeneration); err == nil {
+						continue
+					}
+					logger.Errorf(ctx, "Realtime OpenAI ReadMessage model: %s, error: %v", c.model, err)
+				}
+
+				shutdown(errorResponse(err))
 
-			if messageType == -1 {
 				return
 			}
 
@@ -145,23 +441,170 @@ func (c *RealtimeClient) Realtime(ctx context.Context, requestChan chan *model.R
 				ConnTime:    duration - now,
 			}
 
+			if messageType == websocket.TextMessage {
+				if response.Event, err = ParseRealtimeServerEvent(message); err != nil {
+					logger.Errorf(ctx, "Realtime OpenAI ReadMessage model: %s, message: %s, error: %v", c.model, message, err)
+				} else if response.Event.Type == consts.REALTIME_EVENT_RESPONSE_DONE && response.Event.Response != nil && response.Event.Response.Usage != nil {
+					response.Usage = convRealtimeUsage(response.Event.Response.Usage)
+				}
+			}
+
 			end := gtime.TimestampMilli()
 			response.Duration = end - duration
 			response.TotalTime = end - now
 
-			responseChan <- response
+			send(response)
 		}
 
 	}, nil); err != nil {
 		logger.Errorf(ctx, "Realtime OpenAI ReadMessage model: %s, error: %v", c.model, err)
-		return
+		shutdown(errorResponse(err))
+		return nil, err
 	}
 
 	return responseChan, nil
 }
 
+// connect 建立连接并开启心跳
+func (c *RealtimeClient) connect(ctx context.Context) (*util.WebSocketConn, error) {
+
+	requestHeader := http.Header{
+		"Authorization": {"Bearer " + c.key},
+		"OpenAI-Beta":   {"realtime=v1"},
+	}
+
+	if c.isAzure {
+		requestHeader = http.Header{
+			"api-key": {c.key},
+		}
+	}
+
+	conn, err := util.WebSocketClient(ctx, c.getWebSocketUrl(ctx), requestHeader, 0, nil, c.proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.options.PingInterval >= 0 {
+
+		pingInterval := c.options.PingInterval
+		if pingInterval == 0 {
+			pingInterval = 30 * time.Second
+		}
+
+		pongTimeout := c.options.PongTimeout
+		if pongTimeout <= 0 {
+			pongTimeout = 10 * time.Second
+		}
+
+		if err = conn.KeepAlive(ctx, pingInterval, pongTimeout); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
 func (c *RealtimeClient) getWebSocketUrl(ctx context.Context) string {
-	webSocketUrl := gstr.Replace(gstr.Replace(fmt.Sprintf("%s%s?model=%s", c.baseURL, c.path, c.model), "https://", "wss://"), "http://", "ws://")
+	webSocketUrl := fmt.Sprintf("%s%s?model=%s", c.baseURL, c.path, c.model)
+
+	if c.isAzure {
+		// 与go-openai的默认部署名映射保持一致, 去掉模型名中的.和:
+		deployment := gstr.ReplaceByMap(c.model, map[string]string{".": "", ":": ""})
+		webSocketUrl = fmt.Sprintf("%s%s?api-version=%s&deployment=%s", c.baseURL, c.path, c.apiVersion, deployment)
+	}
+
+	webSocketUrl = gstr.Replace(gstr.Replace(webSocketUrl, "https://", "wss://"), "http://", "ws://")
 	logger.Infof(ctx, "Realtime OpenAI model: %s, webSocketUrl: %s", c.model, webSocketUrl)
 	return webSocketUrl
 }
+
+// ParseRealtimeServerEvent 解析服务端事件
+func ParseRealtimeServerEvent(message []byte) (*model.RealtimeServerEvent, error) {
+
+	event := new(model.RealtimeServerEvent)
+	if err := gjson.Unmarshal(message, event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func RealtimeSessionUpdate(session *model.RealtimeSession) *model.RealtimeRequest {
+	return &model.RealtimeRequest{
+		Event: &model.RealtimeClientEvent{
+			Type:    consts.REALTIME_EVENT_SESSION_UPDATE,
+			Session: session,
+		},
+	}
+}
+
+// RealtimeInputAudioBufferAppend audio为原始音频数据, 发送时进行base64编码
+func RealtimeInputAudioBufferAppend(audio []byte) *model.RealtimeRequest {
+	return &model.RealtimeRequest{
+		Event: &model.RealtimeClientEvent{
+			Type:  consts.REALTIME_EVENT_INPUT_AUDIO_BUFFER_APPEND,
+			Audio: base64.StdEncoding.EncodeToString(audio),
+		},
+	}
+}
+
+func RealtimeInputAudioBufferCommit() *model.RealtimeRequest {
+	return &model.RealtimeRequest{
+		Event: &model.RealtimeClientEvent{
+			Type: consts.REALTIME_EVENT_INPUT_AUDIO_BUFFER_COMMIT,
+		},
+	}
+}
+
+func RealtimeInputAudioBufferClear() *model.RealtimeRequest {
+	return &model.RealtimeRequest{
+		Event: &model.RealtimeClientEvent{
+			Type: consts.REALTIME_EVENT_INPUT_AUDIO_BUFFER_CLEAR,
+		},
+	}
+}
+
+func RealtimeConversationItemCreate(item *model.RealtimeItem) *model.RealtimeRequest {
+	return &model.RealtimeRequest{
+		Event: &model.RealtimeClientEvent{
+			Type: consts.REALTIME_EVENT_CONVERSATION_ITEM_CREATE,
+			Item: item,
+		},
+	}
+}
+
+// RealtimeResponseCreate config为空时使用会话配置
+func RealtimeResponseCreate(config *model.RealtimeResponseConfig) *model.RealtimeRequest {
+	return &model.RealtimeRequest{
+		Event: &model.RealtimeClientEvent{
+			Type:     consts.REALTIME_EVENT_RESPONSE_CREATE,
+			Response: config,
+		},
+	}
+}
+
+func RealtimeResponseCancel() *model.RealtimeRequest {
+	return &model.RealtimeRequest{
+		Event: &model.RealtimeClientEvent{
+			Type: consts.REALTIME_EVENT_RESPONSE_CANCEL,
+		},
+	}
+}
+
+func convRealtimeUsage(usage *model.RealtimeUsage) *model.Usage {
+	return &model.Usage{
+		PromptTokens:     usage.InputTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      usage.TotalTokens,
+		PromptTokensDetails: &openai.PromptTokensDetails{
+			AudioTokens:  usage.InputTokenDetails.AudioTokens,
+			CachedTokens: usage.InputTokenDetails.CachedTokens,
+			TextTokens:   usage.InputTokenDetails.TextTokens,
+		},
+		CompletionTokensDetails: &openai.CompletionTokensDetails{
+			AudioTokens: usage.OutputTokenDetails.AudioTokens,
+			TextTokens:  usage.OutputTokenDetails.TextTokens,
+		},
+	}
+}
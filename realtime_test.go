@@ -0,0 +1,91 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/iimeta/fastapi-sdk/model"
+)
+
+func TestRealtimeReconnect(t *testing.T) {
+
+	var connections atomic.Int32
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// 每个连接回显一条消息后断开, 模拟多次互不相关的断线
+		messageType, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		connections.Add(1)
+
+		_ = conn.WriteMessage(messageType, message)
+	}))
+	defer server.Close()
+
+	client := NewRealtimeClient(context.Background(), "gpt-4o-realtime-preview", "sk-test", server.URL, "").SetOptions(model.RealtimeOptions{
+		PingInterval:      -1,
+		MaxReconnects:     1,
+		ReconnectInterval: 10 * time.Millisecond,
+	})
+
+	requestChan := make(chan *model.RealtimeRequest)
+
+	responseChan, err := client.Realtime(context.Background(), requestChan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+
+		select {
+		case requestChan <- &model.RealtimeRequest{MessageType: websocket.TextMessage, Message: []byte(`{"type":"ping"}`)}:
+		case response := <-responseChan:
+			t.Fatalf("outage %d: session closed with error %v, MaxReconnects should apply per outage", i, response.Error)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("outage %d: timeout sending request", i)
+		}
+
+		select {
+		case response := <-responseChan:
+			if response.Error != nil {
+				t.Fatalf("outage %d: response error = %v, MaxReconnects should apply per outage", i, response.Error)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("outage %d: timeout waiting for response", i)
+		}
+
+		// 等待读协程发现断线并完成重连
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	select {
+	case requestChan <- nil:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout sending close")
+	}
+
+	select {
+	case <-responseChan:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for close")
+	}
+
+	if got := connections.Load(); got != 3 {
+		t.Errorf("connections = %d, want 3", got)
+	}
+}
@@ -24,6 +24,13 @@ type ApiError struct {
 	Param          *string `json:"param,omitempty"`
 }
 
+// MidjourneyError midjourney-proxy提交任务返回的非成功code
+type MidjourneyError struct {
+	Code        int    `json:"code"`
+	Description string `json:"description"`
+	Result      string `json:"result,omitempty"`
+}
+
 // RequestError provides information about generic request sdkerr.
 type RequestError struct {
 	HttpStatusCode int
@@ -94,6 +101,10 @@ func (e *ApiError) UnmarshalJSON(data []byte) (err error) {
 	return json.Unmarshal(rawMap["code"], &e.Code)
 }
 
+func (e *MidjourneyError) Error() string {
+	return fmt.Sprintf("midjourney error, code: %d, description: %s", e.Code, e.Description)
+}
+
 func (e *RequestError) Error() string {
 	return fmt.Sprintf("error, status code: %d, response: %s", e.HttpStatusCode, e.Err)
 }
@@ -118,3 +129,11 @@ func NewRequestError(httpStatusCode int, err error) error {
 		Err:            err,
 	}
 }
+
+func NewMidjourneyError(code int, description, result string) error {
+	return &MidjourneyError{
+		Code:        code,
+		Description: description,
+		Result:      result,
+	}
+}
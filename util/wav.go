@@ -0,0 +1,221 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Wav PCM格式的WAV音频
+type Wav struct {
+	AudioFormat   uint16
+	Channels      uint16
+	SampleRate    uint32
+	BitsPerSample uint16
+	Data          []byte
+}
+
+// WavChunk 切分后的音频分片, Offset为分片在原音频中的起始时间(秒)
+type WavChunk struct {
+	Offset float64
+	Data   []byte
+}
+
+// ParseWav 解析WAV音频, 仅支持16位PCM
+func ParseWav(data []byte) (*Wav, error) {
+
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, errors.New("invalid wav data")
+	}
+
+	wav := new(Wav)
+	hasFmt := false
+
+	for pos := 12; pos+8 <= len(data); {
+
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		pos += 8
+
+		if size < 0 || pos+size > len(data) {
+			// 部分录音软件写入的data长度不准确, 以实际长度为准
+			if id == "data" {
+				size = len(data) - pos
+			} else {
+				return nil, errors.New("invalid wav chunk size")
+			}
+		}
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, errors.New("invalid wav fmt chunk")
+			}
+			wav.AudioFormat = binary.LittleEndian.Uint16(data[pos : pos+2])
+			wav.Channels = binary.LittleEndian.Uint16(data[pos+2 : pos+4])
+			wav.SampleRate = binary.LittleEndian.Uint32(data[pos+4 : pos+8])
+			wav.BitsPerSample = binary.LittleEndian.Uint16(data[pos+14 : pos+16])
+			hasFmt = true
+		case "data":
+			wav.Data = data[pos : pos+size]
+		}
+
+		// chunk按2字节对齐
+		pos += size + size%2
+	}
+
+	if !hasFmt || wav.Data == nil {
+		return nil, errors.New("invalid wav data, missing fmt or data chunk")
+	}
+
+	// 1: PCM, 0xFFFE: WAVE_FORMAT_EXTENSIBLE
+	if (wav.AudioFormat != 1 && wav.AudioFormat != 0xFFFE) || wav.BitsPerSample != 16 || wav.Channels == 0 || wav.SampleRate == 0 {
+		return nil, errors.New("only 16-bit PCM wav is supported")
+	}
+
+	return wav, nil
+}
+
+// BlockAlign 每个采样帧的字节数
+func (w *Wav) BlockAlign() int {
+	return int(w.Channels) * int(w.BitsPerSample) / 8
+}
+
+// ByteRate 每秒字节数
+func (w *Wav) ByteRate() int {
+	return int(w.SampleRate) * w.BlockAlign()
+}
+
+// Duration 音频时长(秒)
+func (w *Wav) Duration() float64 {
+	return float64(len(w.Data)) / float64(w.ByteRate())
+}
+
+// Encode 使用当前音频格式将PCM数据编码为WAV
+func (w *Wav) Encode(pcm []byte) []byte {
+
+	buffer := bytes.NewBuffer(make([]byte, 0, 44+len(pcm)))
+
+	buffer.WriteString("RIFF")
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(36+len(pcm)))
+	buffer.WriteString("WAVE")
+	buffer.WriteString("fmt ")
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(16))
+	_ = binary.Write(buffer, binary.LittleEndian, uint16(1))
+	_ = binary.Write(buffer, binary.LittleEndian, w.Channels)
+	_ = binary.Write(buffer, binary.LittleEndian, w.SampleRate)
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(w.ByteRate()))
+	_ = binary.Write(buffer, binary.LittleEndian, uint16(w.BlockAlign()))
+	_ = binary.Write(buffer, binary.LittleEndian, w.BitsPerSample)
+	buffer.WriteString("data")
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(len(pcm)))
+	buffer.Write(pcm)
+
+	return buffer.Bytes()
+}
+
+// Split 将音频切分为不超过maxDuration秒的分片
+// splitOnSilence为true时, 在每个分片的后1/4区间内寻找静音段切分, 找不到时按固定时长切分
+// silenceThreshold为静音振幅占满幅的比例, minSilence为最短静音时长(秒)
+func (w *Wav) Split(maxDuration float64, splitOnSilence bool, silenceThreshold, minSilence float64) []WavChunk {
+
+	var (
+		blockAlign = w.BlockAlign()
+		byteRate   = w.ByteRate()
+		maxBytes   = int(maxDuration*float64(byteRate)) / blockAlign * blockAlign
+		chunks     []WavChunk
+	)
+
+	if maxBytes < blockAlign {
+		maxBytes = blockAlign
+	}
+
+	for start := 0; start < len(w.Data); {
+
+		end := start + maxBytes
+
+		if end >= len(w.Data) {
+			end = len(w.Data)
+		} else if splitOnSilence {
+			if cut := w.findSilence(start+maxBytes*3/4, end, silenceThreshold, minSilence); cut > start {
+				end = cut
+			}
+		}
+
+		chunks = append(chunks, WavChunk{
+			Offset: float64(start) / float64(byteRate),
+			Data:   w.Encode(w.Data[start:end]),
+		})
+
+		start = end
+	}
+
+	return chunks
+}
+
+// findSilence 在[from, to)区间内寻找最长的静音段, 返回其中点的字节位置, 未找到时返回-1
+func (w *Wav) findSilence(from, to int, silenceThreshold, minSilence float64) int {
+
+	var (
+		blockAlign = w.BlockAlign()
+		// 以10ms为一帧计算音量
+		frameSize  = w.ByteRate() / 100 / blockAlign * blockAlign
+		minFrames  = int(math.Ceil(minSilence * 100))
+		threshold  = silenceThreshold * math.MaxInt16
+		runStart   = -1
+		bestStart  = -1
+		bestFrames = 0
+		runFrames  = 0
+	)
+
+	if frameSize < blockAlign {
+		frameSize = blockAlign
+	}
+
+	from = from / blockAlign * blockAlign
+
+	for pos := from; pos+frameSize <= to; pos += frameSize {
+
+		if w.rms(w.Data[pos:pos+frameSize]) <= threshold {
+
+			if runStart < 0 {
+				runStart = pos
+				runFrames = 0
+			}
+
+			runFrames++
+
+			if runFrames > bestFrames {
+				bestStart = runStart
+				bestFrames = runFrames
+			}
+
+		} else {
+			runStart = -1
+		}
+	}
+
+	if bestStart < 0 || bestFrames < minFrames {
+		return -1
+	}
+
+	return bestStart + bestFrames/2*frameSize
+}
+
+// rms 计算一帧PCM数据的均方根振幅
+func (w *Wav) rms(frame []byte) float64 {
+
+	samples := len(frame) / 2
+	if samples == 0 {
+		return 0
+	}
+
+	var sum float64
+	for i := 0; i+1 < len(frame); i += 2 {
+		sample := float64(int16(binary.LittleEndian.Uint16(frame[i : i+2])))
+		sum += sample * sample
+	}
+
+	return math.Sqrt(sum / float64(samples))
+}
@@ -0,0 +1,120 @@
+package util
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// pcm 生成单声道16位PCM数据, amplitude返回第i个采样的振幅
+func pcm(samples int, amplitude func(i int) int16) []byte {
+
+	data := make([]byte, samples*2)
+	for i := 0; i < samples; i++ {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(amplitude(i)))
+	}
+
+	return data
+}
+
+func TestParseWav(t *testing.T) {
+
+	wav := &Wav{Channels: 1, SampleRate: 1000, BitsPerSample: 16}
+	valid := wav.Encode(pcm(1000, func(i int) int16 { return 1000 }))
+
+	eightBit := append([]byte(nil), valid...)
+	binary.LittleEndian.PutUint16(eightBit[34:36], 8)
+
+	truncated := append([]byte(nil), valid[:len(valid)-100]...)
+
+	tests := []struct {
+		name         string
+		data         []byte
+		wantErr      bool
+		wantDuration float64
+	}{
+		{name: "valid", data: valid, wantDuration: 1},
+		{name: "data size larger than file", data: truncated, wantDuration: 0.95},
+		{name: "not riff", data: []byte("ID3\x03\x00\x00\x00\x00\x00\x00\x00\x00"), wantErr: true},
+		{name: "too short", data: []byte("RIFF"), wantErr: true},
+		{name: "8-bit pcm", data: eightBit, wantErr: true},
+		{name: "missing data chunk", data: valid[:36], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			got, err := ParseWav(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWav() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && math.Abs(got.Duration()-tt.wantDuration) > 1e-9 {
+				t.Errorf("Duration() = %f, want %f", got.Duration(), tt.wantDuration)
+			}
+		})
+	}
+}
+
+func TestWavSplit(t *testing.T) {
+
+	// 1kHz采样率, 每个采样即1ms
+	loud := func(i int) int16 { return 10000 }
+
+	// 第800-900ms为静音
+	silentAt800 := func(i int) int16 {
+		if i >= 800 && i < 900 {
+			return 0
+		}
+		return 10000
+	}
+
+	tests := []struct {
+		name           string
+		samples        int
+		amplitude      func(i int) int16
+		maxDuration    float64
+		splitOnSilence bool
+		wantOffsets    []float64
+	}{
+		{name: "single chunk", samples: 500, amplitude: loud, maxDuration: 1, wantOffsets: []float64{0}},
+		{name: "fixed duration", samples: 2500, amplitude: loud, maxDuration: 1, wantOffsets: []float64{0, 1, 2}},
+		{name: "no silence falls back to fixed duration", samples: 1500, amplitude: loud, maxDuration: 1, splitOnSilence: true, wantOffsets: []float64{0, 1}},
+		{name: "split in the middle of silence", samples: 1500, amplitude: silentAt800, maxDuration: 1, splitOnSilence: true, wantOffsets: []float64{0, 0.85}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			wav := &Wav{AudioFormat: 1, Channels: 1, SampleRate: 1000, BitsPerSample: 16, Data: pcm(tt.samples, tt.amplitude)}
+
+			chunks := wav.Split(tt.maxDuration, tt.splitOnSilence, 0.01, 0.05)
+			if len(chunks) != len(tt.wantOffsets) {
+				t.Fatalf("len(chunks) = %d, want %d", len(chunks), len(tt.wantOffsets))
+			}
+
+			total := 0
+			for i, chunk := range chunks {
+
+				if math.Abs(chunk.Offset-tt.wantOffsets[i]) > 1e-9 {
+					t.Errorf("chunks[%d].Offset = %f, want %f", i, chunk.Offset, tt.wantOffsets[i])
+				}
+
+				parsed, err := ParseWav(chunk.Data)
+				if err != nil {
+					t.Fatalf("chunks[%d] is not a valid wav: %v", i, err)
+				}
+
+				if parsed.Duration() > tt.maxDuration {
+					t.Errorf("chunks[%d] duration = %f, exceeds %f", i, parsed.Duration(), tt.maxDuration)
+				}
+
+				total += len(parsed.Data)
+			}
+
+			if total != len(wav.Data) {
+				t.Errorf("total pcm bytes = %d, want %d", total, len(wav.Data))
+			}
+		})
+	}
+}
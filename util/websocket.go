@@ -2,17 +2,25 @@ package util
 
 import (
 	"context"
+	"errors"
 	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/os/grpool"
 	"github.com/gorilla/websocket"
 	"github.com/iimeta/fastapi-sdk/logger"
+	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 )
 
 type WebSocketConn struct {
 	conn     *websocket.Conn
 	response *http.Response
+	// gorilla/websocket不支持并发写, 写操作需串行
+	writeMutex sync.Mutex
+	closeOnce  sync.Once
+	closed     chan struct{}
 }
 
 func WebSocketClient(ctx context.Context, wsURL string, requestHeader http.Header, messageType int, message []byte, proxyURL string) (*WebSocketConn, error) {
@@ -33,7 +41,7 @@ func WebSocketClient(ctx context.Context, wsURL string, requestHeader http.Heade
 		}
 	}
 
-	conn, response, err := client.Dial(wsURL, requestHeader)
+	conn, response, err := client.DialContext(ctx, wsURL, requestHeader)
 	if err != nil {
 		logger.Error(ctx, err)
 
@@ -71,31 +79,39 @@ func WebSocketClient(ctx context.Context, wsURL string, requestHeader http.Heade
 	return &WebSocketConn{
 		conn:     conn,
 		response: response,
+		closed:   make(chan struct{}),
 	}, nil
 }
 
+// ReadMessage 读取消息, 对端正常关闭时返回io.EOF, 其它错误时关闭连接并返回错误
 func (c *WebSocketConn) ReadMessage(ctx context.Context) (int, []byte, error) {
 
-	for {
+	messageType, message, err := c.conn.ReadMessage()
+	if err != nil {
 
-		messageType, message, err := c.conn.ReadMessage()
-		if err != nil && websocket.IsUnexpectedCloseError(err) {
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+			err = io.EOF
+		} else if !c.IsClosed() {
 			logger.Error(ctx, err)
+		}
 
-			if err := c.Close(); err != nil {
-				logger.Error(ctx, err)
-			}
-
-			return 0, nil, err
+		if err := c.Close(); err != nil {
+			logger.Error(ctx, err)
 		}
 
-		return messageType, message, nil
+		return messageType, nil, err
 	}
+
+	return messageType, message, nil
 }
 
 func (c *WebSocketConn) WriteMessage(ctx context.Context, messageType int, message []byte) error {
 
 	if messageType != 0 && message != nil {
+
+		c.writeMutex.Lock()
+		defer c.writeMutex.Unlock()
+
 		if err := c.conn.WriteMessage(messageType, message); err != nil {
 			logger.Error(ctx, err)
 			return err
@@ -108,6 +124,10 @@ func (c *WebSocketConn) WriteMessage(ctx context.Context, messageType int, messa
 func (c *WebSocketConn) WriteJSON(ctx context.Context, message interface{}) error {
 
 	if message != nil {
+
+		c.writeMutex.Lock()
+		defer c.writeMutex.Unlock()
+
 		if err := c.conn.WriteJSON(message); err != nil {
 			logger.Error(ctx, err)
 			return err
@@ -117,15 +137,80 @@ func (c *WebSocketConn) WriteJSON(ctx context.Context, message interface{}) erro
 	return nil
 }
 
-func (c *WebSocketConn) Close() (err error) {
+// KeepAlive 按pingInterval发送ping, 超过pingInterval+pongTimeout未收到pong时读操作超时返回错误
+// 需在开始读取消息前调用, 连接关闭或ctx取消后停止
+func (c *WebSocketConn) KeepAlive(ctx context.Context, pingInterval, pongTimeout time.Duration) error {
+
+	if err := c.conn.SetReadDeadline(time.Now().Add(pingInterval + pongTimeout)); err != nil {
+		return err
+	}
+
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pingInterval + pongTimeout))
+	})
+
+	return grpool.AddWithRecover(ctx, func(ctx context.Context) {
+
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.closed:
+				return
+			case <-ticker.C:
+				if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pongTimeout)); err != nil {
+					if !c.IsClosed() {
+						logger.Error(ctx, err)
+					}
+					return
+				}
+			}
+		}
 
-	if e := c.response.Body.Close(); e != nil {
-		err = e
+	}, nil)
+}
+
+// WriteClose 发送关闭帧, 对端回复关闭帧后ReadMessage返回io.EOF
+func (c *WebSocketConn) WriteClose(ctx context.Context) error {
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	if err := c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second)); err != nil && !errors.Is(err, websocket.ErrCloseSent) {
+		logger.Error(ctx, err)
+		return err
 	}
 
-	if e := c.conn.Close(); e != nil {
-		err = e
+	return nil
+}
+
+func (c *WebSocketConn) IsClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
 	}
+}
+
+// Close 关闭连接, 可重复调用
+func (c *WebSocketConn) Close() (err error) {
+
+	c.closeOnce.Do(func() {
+
+		close(c.closed)
+
+		if e := c.response.Body.Close(); e != nil {
+			err = e
+		}
+
+		if e := c.conn.Close(); e != nil {
+			err = e
+		}
+	})
 
 	return err
 }
@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/os/grpool"
 	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
 	"github.com/iimeta/fastapi-sdk/common"
 	"github.com/iimeta/fastapi-sdk/consts"
 	"github.com/iimeta/fastapi-sdk/logger"
@@ -41,7 +44,7 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 		TopP:        request.TopP,
 		Stream:      request.Stream,
 		Stop:        request.Stop,
-		Tools:       request.Tools,
+		Tools:       convTools(request),
 		ToolChoice:  request.ToolChoice,
 		UserId:      request.User,
 	}
@@ -88,6 +91,8 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 		Usage:   chatCompletionRes.Usage,
 	}
 
+	res.Citations = convCitations(chatCompletionRes.WebSearch)
+
 	for _, choice := range chatCompletionRes.Choices {
 		res.Choices = append(res.Choices, model.ChatCompletionChoice{
 			Index: choice.Index,
@@ -101,6 +106,7 @@ func (c *Client) ChatCompletion(ctx context.Context, request model.ChatCompletio
 				ToolCalls:    choice.Message.ToolCalls,
 				ToolCallID:   choice.Message.ToolCallID,
 				Audio:        choice.Message.Audio,
+				Citations:    res.Citations,
 			},
 			FinishReason: choice.FinishReason,
 		})
@@ -135,7 +141,7 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 		TopP:        request.TopP,
 		Stream:      request.Stream,
 		Stop:        request.Stop,
-		Tools:       request.Tools,
+		Tools:       convTools(request),
 		ToolChoice:  request.ToolChoice,
 		UserId:      request.User,
 	}
@@ -180,6 +186,8 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 			logger.Infof(ctx, "ChatCompletionStream ZhipuAI model: %s connTime: %d ms, duration: %d ms, totalTime: %d ms", request.Model, duration-now, end-duration, end-now)
 		}()
 
+		isCitationsSent := false
+
 		for {
 
 			streamResponse, err := stream.Recv()
@@ -241,6 +249,12 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 				ConnTime: duration - now,
 			}
 
+			// 搜索结果只在首次返回时下发
+			if len(chatCompletionRes.WebSearch) > 0 && !isCitationsSent {
+				response.Citations = convCitations(chatCompletionRes.WebSearch)
+				isCitationsSent = true
+			}
+
 			for _, choice := range chatCompletionRes.Choices {
 				response.Choices = append(response.Choices, model.ChatCompletionChoice{
 					Index: choice.Index,
@@ -251,6 +265,7 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 						ToolCalls:    choice.Delta.ToolCalls,
 						Refusal:      choice.Delta.Refusal,
 						Audio:        choice.Delta.Audio,
+						Citations:    response.Citations,
 					},
 					FinishReason: choice.FinishReason,
 				})
@@ -294,3 +309,51 @@ func (c *Client) ChatCompletionStream(ctx context.Context, request model.ChatCom
 
 	return responseChan, nil
 }
+
+// convTools 开启联网搜索时追加web_search工具
+func convTools(request model.ChatCompletionRequest) any {
+
+	if !request.EnableSearch {
+		return request.Tools
+	}
+
+	tools := gconv.Interfaces(request.Tools)
+	for _, tool := range tools {
+		if gconv.Map(tool)["type"] == "web_search" {
+			return tools
+		}
+	}
+
+	return append(tools, g.Map{
+		"type": "web_search",
+		"web_search": g.Map{
+			"enable":        true,
+			"search_result": true,
+		},
+	})
+}
+
+// convCitations 将web_search工具返回的搜索结果转换为Citations, 序号取自refer, 如[ref_1]
+func convCitations(webSearch []model.ZhipuAIWebSearch) []model.Citation {
+
+	if len(webSearch) == 0 {
+		return nil
+	}
+
+	citations := make([]model.Citation, 0, len(webSearch))
+	for i, result := range webSearch {
+
+		index := gconv.Int(gstr.TrimLeftStr(gstr.Trim(result.Refer, "[]"), "ref_"))
+		if index == 0 {
+			index = i + 1
+		}
+
+		citations = append(citations, model.Citation{
+			Index: index,
+			URL:   result.Link,
+			Title: result.Title,
+		})
+	}
+
+	return citations
+}
@@ -0,0 +1,51 @@
+package zhipuai
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/iimeta/fastapi-sdk/model"
+)
+
+func TestConvCitations(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		webSearch []model.ZhipuAIWebSearch
+		want      []model.Citation
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "index from refer",
+			webSearch: []model.ZhipuAIWebSearch{
+				{Title: "B", Link: "https://b.com", Refer: "[ref_2]"},
+				{Title: "A", Link: "https://a.com", Refer: "ref_1"},
+			},
+			want: []model.Citation{
+				{Index: 2, URL: "https://b.com", Title: "B"},
+				{Index: 1, URL: "https://a.com", Title: "A"},
+			},
+		},
+		{
+			name: "position when refer is missing",
+			webSearch: []model.ZhipuAIWebSearch{
+				{Title: "A", Link: "https://a.com"},
+				{Title: "B", Link: "https://b.com", Refer: "unknown"},
+			},
+			want: []model.Citation{
+				{Index: 1, URL: "https://a.com", Title: "A"},
+				{Index: 2, URL: "https://b.com", Title: "B"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := convCitations(tt.webSearch); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convCitations() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}